@@ -1,10 +1,17 @@
 package main
 import (
+	"embed"
+	"errors"
+	"flag"
 	"fmt"
+	"os"
 	"runtime"
 
 	"./wrapper"
+	"./gpu"
 	"./objects"
+	"./scene"
+	"./camera"
 
 	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
@@ -15,31 +22,59 @@ const windowWidth = 1024
 const windowHeight = 768
 const windowFPS = 60
 
+// Command line flags to render without a visible window (for machines without a display or a GPU)
+var offscreenFrames = flag.Int("offscreen", 0, "render this number of frames offscreen and save them as PNG instead of opening a window")
+var offscreenOutput = flag.String("output", "frame_%03d.png", "path of the frames rendered offscreen (the frame number replaces the format verb)")
+
+// The shaders, textures and data files are embedded in the binary, so it runs from any folder
+//go:embed shaders textures bindings.json materials.json
+var embeddedAssets embed.FS
+
+// Folder with assets to use instead of the embedded ones (to edit the shaders while the app runs)
+var assetsDir = flag.String("assets", "", "read the assets from this folder first, the embedded ones are used for the missing files")
+
 /* Define buffer object indices */
 var positionBufferObject, colourObject, normalsBufferObject uint32
 
-var shaderProgram uint32        /* Identifier for the shader prgoram */
-var vertexArrayObject uint32            /* Vertex array (Containor) object. This is the index of the VAO that will be the container for
-					   our buffer objects */
+var shaderProgram *gpu.ShaderProgram  /* The shader program, rebuilt when the shader files change */
 
 var colourmode objects.ColorMode    /* Index of a uniform to switch the colour mode in the vertex shader
 					  I've included this to show you how to pass in an unsigned integer into
 					  your vertex shader. */
 
-/* Position and view globals */
-var angle_x, angle_inc_x, x, scale, z, y float32
-var angle_y, angle_inc_y, angle_z, angle_inc_z float32
+var shademode objects.ShadeMode        // Lighting calculated per vertex (Gouraud) or per fragment (Phong)
+var specularmode objects.SpecularMode  // Specular term calculated with the Phong or Blinn-Phong model
+var usetexture bool                    // Multiplies the colour of the objects with the diffuse texture of their material
 
-var aspect_ratio float32        // Aspect ratio of the window defined in the reshape callback
+var projection *camera.Projection  // Perspective or orthographic, the aspect ratio is updated in the reshape callback
 
-// Uniforms
-var modelUniform, projectionUniform, viewUniform int32
-var colourmodeUniform int32
+var glwrapper *wrapper.Glw      // The window wrapper, used by the callbacks that don't receive it
+var input *wrapper.InputMap     // Maps the keys to the actions of the app
+
+// Uniforms of the materials and the lights (the rest are set by name on the shader program)
+var materialUniforms objects.MaterialUniforms
+var lightUniforms objects.LightUniforms
 
 // Sphere
 var sphere *objects.Sphere
+var sphereLOD *objects.LevelOfDetail  // Picks the resolution of the sphere from its size on screen
+var autoLOD bool                      // The resolution is picked by sphereLOD on every frame instead of with the keys
 var cube *objects.Cube
 
+// Scene graph, the cube and the sphere (with a moon orbiting it) are children of the world node
+var world, cubeNode, sphereNode *scene.Node
+
+// Lights, the keys move the active one and a small sphere is drawn at the position of each point light and spotlight
+var lights []*objects.Light
+var activeLight int
+var lightMarker *objects.Sphere
+
+// Materials of the objects, loaded from materials.json
+var materials map[string]*objects.Material
+
+// Camera, controlled with the mouse (left drag rotates, right drag pans and the scroll wheel zooms)
+var viewCamera *camera.Camera
+
 
 // Define vertices for a cube in 12 triangles
 var vertexPositions = []float32{
@@ -137,46 +172,6 @@ var vertexColours = []float32{
 	0.0, 1.0, 1.0, 1.0,
 }
 
-/* Manually specified normals for our cube */
-var normals = []float32{
-	0, 0, -1,
-	0, 0, -1,
-	0, 0, -1,
-	0, 0, -1,
-	0, 0, -1,
-	0, 0, -1,
-	1, 0, 0,
-	1, 0, 0,
-	1, 0, 0,
-	1, 0, 0,
-	1, 0, 0,
-	1, 0, 0,
-	0, 0, 1,
-	0, 0, 1,
-	0, 0, 1,
-	0, 0, 1,
-	0, 0, 1,
-	0, 0, 1,
-	-1, 0, 0,
-	-1, 0, 0,
-	-1, 0, 0,
-	-1, 0, 0,
-	-1, 0, 0,
-	-1, 0, 0,
-	0, -1, 0,
-	0, -1, 0,
-	0, -1, 0,
-	0, -1, 0,
-	0, -1, 0,
-	0, -1, 0,
-	0, 1, 0,
-	0, 1, 0,
-	0, 1, 0,
-	0, 1, 0,
-	0, 1, 0,
-	0, 1, 0,
-}
-
 /////////////////////////////////////////////////////////////////////////////////////
 ////////////////////////////////// Initialization ///////////////////////////////////
 /////////////////////////////////////////////////////////////////////////////////////
@@ -193,75 +188,240 @@ func main() {
 	glw := wrapper.NewWrapper(windowWidth, windowHeight, "Lab 3: Lights")
 	glw.SetFPS(windowFPS)
 
+	// Renders offscreen if requested
+	flag.Parse()
+	gpu.SetAssets(gpu.NewAssetFS(embeddedAssets, assetFolder()))
+	if *offscreenFrames > 0 {
+		glw.SetOffscreen(*offscreenFrames, *offscreenOutput)
+	}
+
 	// Creates the Window
-	glw.CreateWindow()
+	if _, err := glw.CreateWindow(); err != nil {
+		exitWithError(err)
+	}
+
+	// Loads the key bindings
+	input = wrapper.NewInputMap()
+	registerActions(input)
+	if err := input.LoadBindings("./bindings.json"); err != nil {
+		glw.Terminate()
+		exitWithError(err)
+	}
+	fmt.Print(input.Help())
 
 	// Sets the Event Callbacks
 	glw.SetRenderCallback(drawLoop)
-	glw.SetKeyCallBack(keyCallback)
+	glw.SetUpdateCallback(update)
+	glw.SetKeyCallBack(input.KeyCallback)
 	glw.SetReshapeCallback(reshape)
+	glw.SetMouseButtonCallback(mouseButton)
+	glw.SetCursorPosCallback(cursorPos)
+	glw.SetScrollCallback(scroll)
 
 	// Initializes the App
-	InitApp(glw)
+	if err := InitApp(glw); err != nil {
+		glw.Terminate()
+		exitWithError(err)
+	}
 
 	// Starts the Rendering Loop
-	glw.StartLoop()
+	if err := glw.StartLoop(); err != nil {
+		exitWithError(err)
+	}
 
 	// Sets the Viewport (Important !!, this has to run after the loop!!)
 	defer gl.Viewport(0, 0, windowWidth, windowHeight)
 }
 
+//
+// Asset Folder
+// Returns the folder given with -assets, which is read before the embedded assets, and says which assets are used.
+// The embedded shaders never change, so without a folder the shaders aren't reloaded.
+//
+// @return folder (string) the folder, empty to use only the embedded assets
+//
+func assetFolder() string {
+	if *assetsDir == "" {
+		fmt.Println("Using the embedded assets, the shaders are not reloaded when they change (run with -assets <folder> to edit them)")
+	} else {
+		fmt.Printf("Reading the assets from %s first, the embedded ones are used for the missing files\n", *assetsDir)
+	}
+
+	return *assetsDir
+}
+
+//
+// Exit With Error
+// Prints an error that stops the app from starting (with a hint if there is no usable OpenGL context) and exits
+//
+// @param err (error) the error
+//
+func exitWithError(err error) {
+	fmt.Fprintln(os.Stderr, "Error:", err)
+
+	var contextErr *wrapper.ContextError
+	if errors.As(err, &contextErr) {
+		fmt.Fprintln(os.Stderr, "The app needs a display and OpenGL 3.3, see \"Render Offscreen\" in the readme to run it without a GPU")
+	}
+
+	os.Exit(1)
+}
+
 //
 // Init App
 // This function initializes the variables and sets up the environment.
 //
 // @param wrapper (*wrapper.Glw) the window wrapper
 //
-func InitApp(glw *wrapper.Glw) {
-	/* Set the object transformation controls to their initial values */
-	x = 0.05;
-	y = 0;
-	z = 0;
-	angle_x = 0
-	angle_y = 0
-	angle_z = 0
-	angle_inc_x = 0
-	angle_inc_y = 0
-	angle_inc_z = 0
-	scale = 1.0;
-	aspect_ratio = 1.3333
+// @return error (error) the error (if the materials, the textures or the shaders can't be loaded)
+//
+func InitApp(glw *wrapper.Glw) error {
+	glwrapper = glw
 	colourmode = objects.COLOR_SOLID
+	shademode = objects.SHADE_PER_FRAGMENT
+	specularmode = objects.SPECULAR_BLINN_PHONG
 	var numLats uint32 = 20        // Number of latitudes in our sphere
 	var numLongs uint32 = 20        // Number of longitudes in our sphere
 
-	// Generate index (name) for one vertex array object
-	gl.GenVertexArrays(1, &vertexArrayObject);
-
-	// Create the vertex array object and make it current
-	gl.BindVertexArray(vertexArrayObject);
+	// Everything created here is released when the window is closed
+	glw.SetReleaseCallback(releaseApp)
 
-
-	// Create the Cube Object
+	// Create the Cube Object (each mesh has its own vertex array object, configured when it is made)
+	// Its triangles are wound clockwise seen from outside, so the flat normals are flipped to point outwards
+	normals := objects.FlatNormals(vertexPositions)
+	objects.FlipNormals(normals)
 	cube = objects.NewCube(&vertexPositions, &vertexColours, &normals)
 	cube.MakeVBO()
 
 	// create the sphere object
 	sphere = objects.NewSphere(numLats, numLongs);
-	sphere.MakeSphereVBO()
+	sphere.MakeVBO()
+
+	// Levels change when the size is 15% past their threshold, so the sphere doesn't flicker between two of them
+	sphereLOD = objects.NewLevelOfDetail(objects.SPHERE_DETAIL_LEVELS, 0.15)
+	autoLOD = false
+
+	// Build the scene graph, with the objects at their initial position and scale
+	world = scene.NewNode("world", nil)
+
+	cubeNode = world.AddChild(scene.NewNode("cube", cube))
+	cubeNode.Position = mgl32.Vec3{0.55, 0, 0}
 
-	// Creates the Shader Program
-	var err error; shaderProgram, err = wrapper.LoadShader("./shaders/basic.vert", "./shaders/basic.frag")
+	sphereNode = world.AddChild(scene.NewNode("sphere", sphere))
+	sphereNode.Position = mgl32.Vec3{-0.55, 0, 0}
+	sphereNode.Scale = mgl32.Vec3{1.0 / 3.0, 1.0 / 3.0, 1.0 / 3.0}
 
-	// If there is any error loading the shaders, it panics
+	// The moon is attached to a pivot at the centre of the sphere, spinning the pivot makes the moon orbit
+	orbit := sphereNode.AddChild(scene.NewNode("orbit", nil))
+	orbit.Spin = mgl32.Vec3{0, 1.2, 0}
+
+	moon := orbit.AddChild(scene.NewNode("moon", sphere))
+	moon.Position = mgl32.Vec3{2.0, 0, 0}
+	moon.Scale = mgl32.Vec3{0.25, 0.25, 0.25}
+
+	// Create a point light above and in front of the objects, a dim directional light from the left
+	// and a spotlight pointing down at the cube (only the first light adds ambient light)
+	keyLight := objects.NewPointLight(mgl32.Vec3{1.0, 1.0, 2.0})
+
+	fillLight := objects.NewDirectionalLight(mgl32.Vec3{1.0, -0.5, -0.5})
+	fillLight.Ambient = mgl32.Vec3{0, 0, 0}
+	fillLight.Diffuse = mgl32.Vec3{0.15, 0.15, 0.25}
+	fillLight.Specular = mgl32.Vec3{0, 0, 0}
+
+	spotLight := objects.NewSpotLight(mgl32.Vec3{0.55, 1.5, 0.5}, mgl32.Vec3{0, -1.0, -0.35}, 12.0, 18.0)
+	spotLight.Ambient = mgl32.Vec3{0, 0, 0}
+	spotLight.Diffuse = mgl32.Vec3{1.0, 0.85, 0.5}
+
+	lights = []*objects.Light{keyLight, fillLight, spotLight}
+	activeLight = 0
+
+	// The markers are lit only by their own (emissive) colour
+	lightMarker = objects.NewSphere(8, 8)
+	lightMarker.MakeVBO()
+	lightMarker.SetMaterial(objects.NewMaterial("light", mgl32.Vec3{0, 0, 0}))
+	lightMarker.GetMaterial().Specular = mgl32.Vec3{0, 0, 0}
+
+	// Create the camera at (0,0,4), looking at the origin
+	viewCamera = camera.NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4.0)
+	viewCamera.SetViewport(glw.Width, glw.Height)
+
+	// Perspective projection: 45° vertical field of view, display range : 0.1 unit <-> 100 units
+	projection = camera.NewProjection(45.0, 0.1, 100.0)
+	projection.SetViewport(glw.GetFramebufferSize())
+
+	// Load the materials and their textures (with mipmaps and repeated outside 0..1)
+	var err error; materials, err = objects.LoadMaterials("./materials.json")
 	if err != nil {
-		panic(err)
+		return err
+	}
+	if err := objects.LoadMaterialTextures(materials, gpu.DefaultTextureOptions()); err != nil {
+		return err
+	}
+
+	// The cube and the sphere (and the moon, which uses the sphere mesh) look different
+	cubeMaterial, found := materials["white_tiles"]
+	if !found {
+		return fmt.Errorf("materials.json has no material %q for the cube", "white_tiles")
+	}
+	sphereMaterial, found := materials["red_plastic"]
+	if !found {
+		return fmt.Errorf("materials.json has no material %q for the sphere", "red_plastic")
+	}
+	cube.SetMaterial(cubeMaterial)
+	sphere.SetMaterial(sphereMaterial)
+
+	// Creates the Shader Program, the shaders size the lights array with the same limit as the app
+	shaderDefines := map[string]string{"MAX_LIGHTS": fmt.Sprint(objects.MAX_LIGHTS)}
+	shaderProgram, err = gpu.NewShaderProgram("./shaders/basic.vert", "./shaders/basic.frag", shaderDefines)
+
+	// If there is any error loading the shaders, the app can't draw anything
+	if err != nil {
+		return err
+	}
+
+	// The material and light uniform locations change when the shaders are edited and rebuilt
+	findUniforms(shaderProgram)
+	shaderProgram.SetReloadCallback(findUniforms)
+
+	return nil
+}
+
+//
+// Release App
+// Deletes the GPU objects created by InitApp (called by the wrapper before the window is destroyed)
+//
+// @param glw (*wrapper.Glw) the window wrapper
+//
+func releaseApp(glw *wrapper.Glw) {
+	// The meshes are nil if InitApp failed before creating them
+	if cube != nil {
+		cube.Release()
+	}
+	if sphere != nil {
+		sphere.Release()
+	}
+	if lightMarker != nil {
+		lightMarker.Release()
 	}
 
-	// Define uniforms to send to vertex shader
-	modelUniform = gl.GetUniformLocation(shaderProgram, gl.Str("model\x00"));
-	colourmodeUniform = gl.GetUniformLocation(shaderProgram, gl.Str("colourmode\x00"));
-	viewUniform = gl.GetUniformLocation(shaderProgram, gl.Str("view\x00"));
-	projectionUniform = gl.GetUniformLocation(shaderProgram, gl.Str("projection\x00"));
+	objects.ReleaseMaterialTextures(materials)
+
+	if shaderProgram != nil {
+		shaderProgram.Release()
+	}
+}
+
+//
+// Find Uniforms
+// Gets the locations of the material and light uniforms of the shader program
+//
+// @param program (*gpu.ShaderProgram) the shader program
+//
+func findUniforms(program *gpu.ShaderProgram) {
+	materialUniforms = objects.NewMaterialUniforms(program.Shader)
+
+	// Define the light uniforms
+	lightUniforms = objects.NewLightUniforms(program.Shader)
 }
 
 /////////////////////////////////////////////////////////////////////////////////////
@@ -282,160 +442,413 @@ func drawLoop(glw *wrapper.Glw) {
 	// Enables Depth
 	gl.Enable(gl.DEPTH_TEST)
 
-	// Sets the Shader program to Use
-	gl.UseProgram(shaderProgram)
-
-	// Define the model transformations for the cube
-	cube.ResetModel()
-	cube.Translate(x + 0.5, y, z)
-	cube.Scale(scale, scale, scale) //scale equally in all axis
-	cube.Rotate(-angle_x, mgl32.Vec3{1, 0, 0}) //rotating in clockwise direction around x-axis
-	cube.Rotate(-angle_y, mgl32.Vec3{0, 1, 0}) //rotating in clockwise direction around y-axis
-	cube.Rotate(-angle_z, mgl32.Vec3{0, 0, 1}) //rotating in clockwise direction around z-axis
+	// Rebuilds the shader program if the shader files were edited, then sets it as the program to use
+	shaderProgram.Poll()
+	shaderProgram.Use()
 
-	// Define the model transformations for our sphere
-	sphere.ResetModel()
-	sphere.Translate(-x - 0.5, 0, 0)
-	sphere.Scale(scale / 3.0, scale / 3.0, scale / 3.0) //scale equally in all axis
-	sphere.Rotate(-angle_x, mgl32.Vec3{1, 0, 0}) //rotating in clockwise direction around x-axis
-	sphere.Rotate(-angle_y, mgl32.Vec3{0, 1, 0}) //rotating in clockwise direction around y-axis
-	sphere.Rotate(-angle_z, mgl32.Vec3{0, 0, 1}) //rotating in clockwise direction around z-axis
-
-	// Projection matrix : 45° Field of View, 4:3 ratio, display range : 0.1 unit <-> 100 units
-	var Projection mgl32.Mat4 = mgl32.Perspective(30.0, aspect_ratio, 0.1, 100.0)
+	// Projection matrix, with the aspect ratio of the framebuffer
+	var Projection mgl32.Mat4 = projection.Matrix()
 
 	// Camera matrix
-	var View mgl32.Mat4 = mgl32.LookAtV(
-		mgl32.Vec3{0, 0, 4}, // Camera is at (0,0,4), in World Space
-		mgl32.Vec3{0, 0, 0}, // and looks at the origin
-		mgl32.Vec3{0, 1, 0}, // Head is up (set to 0,-1,0 to look upside-down)
-	);
+	var View mgl32.Mat4 = viewCamera.View()
 
 	// Send our uniforms variables to the currently bound shader,
-	gl.Uniform1ui(colourmodeUniform, uint32(colourmode))
-	gl.UniformMatrix4fv(viewUniform, 1, false, &View[0])
-	gl.UniformMatrix4fv(projectionUniform, 1, false, &Projection[0])
+	shaderProgram.SetUint("colourmode", uint32(colourmode))
+	shaderProgram.SetMat4("view", View)
+	shaderProgram.SetMat4("projection", Projection)
+	shaderProgram.SetUint("shademode", uint32(shademode))
+	shaderProgram.SetUint("specularmode", uint32(specularmode))
+
+	// Send the lights to the shader, with their positions and directions in eye space
+	lightUniforms.Upload(lights, View)
+
+	// Remakes the sphere at the resolution for its size on screen
+	if autoLOD {
+		updateSphereLOD(View)
+	}
 
-	// Draws the Cube
-	gl.UniformMatrix4fv(modelUniform, 1, false, &cube.Model[0])
-	cube.Draw()
+	// Draws every object of the scene with its own model and normal matrix
+	world.Draw(func(mesh objects.Drawable, Model mgl32.Mat4) {
+		var normalMatrix mgl32.Mat3 = objects.NormalMatrix(Model, View)
 
-	// Draw our sphere
-	gl.UniformMatrix4fv(modelUniform, 1, false, &sphere.Model[0])
-	sphere.DrawSphere()
+		shaderProgram.SetMat4("model", Model)
+		shaderProgram.SetMat3("normalmatrix", normalMatrix)
+		materialUniforms.Upload(mesh.GetMaterial(), usetexture)
+		mesh.Draw()
+	})
 
-	gl.DisableVertexAttribArray(0);
-	gl.UseProgram(0);
+	drawLightMarkers(View)
 
-	/* Modify our animation variables */
-	angle_x += angle_inc_x;
-	angle_y += angle_inc_y;
-	angle_z += angle_inc_z;
+	gl.UseProgram(0);
 }
 
 //
-// key Callback
-// This function gets called when a key is pressed
+// Update Sphere LOD
+// Picks the resolution of the sphere from the biggest size on screen it is drawn at (the moon uses the same mesh)
 //
-// @param window (*glfw.Window) a pointer to the window
-// @param key (glfw.Key) the pressed key
-// @param scancode (int) the scancode
-// @param action (glfw.Action) the state of the key
-// @param mods (glfw.ModifierKey) the pressed modified keys.
+// @param View (mgl32.Mat4) the view matrix
 //
-func keyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	// React only if the key was just pressed
-	if action != glfw.Press {
-		return;
-	}
+func updateSphereLOD(View mgl32.Mat4) {
+	var size float32 = 0
 
-	switch key {
-	// If the Key Excape is pressed, it closes the App
-	case glfw.KeyEscape:
-		if action == glfw.Press {
-			window.SetShouldClose(true)
+	world.Draw(func(mesh objects.Drawable, Model mgl32.Mat4) {
+		if mesh != objects.Drawable(sphere) {
+			return
 		}
-		break
 
-	case glfw.KeyQ:
-		angle_inc_x += 0.05
-		break
+		// The sphere has a radius of 1, scaled by the longest axis of the model matrix
+		var radius float32 = 0
+		for i := 0; i < 3; i++ {
+			if length := Model.Col(i).Vec3().Len(); length > radius {
+				radius = length
+			}
+		}
 
-	case glfw.KeyW:
-		angle_inc_x -= 0.05
-		break
+		centre := View.Mul4(Model).Col(3).Vec3()
+		if projected := projection.ScreenSize(centre, radius); projected > size {
+			size = projected
+		}
+	})
 
-	case glfw.KeyE:
-		angle_inc_y += 0.05
-		break
+	level := sphereLOD.Select(size)
+	if sphere.SetResolution(level.Lats, level.Longs) {
+		fmt.Printf("Sphere LOD: %dx%d (%.0f pixels) \n", level.Lats, level.Longs, size)
+	}
+}
 
-	case glfw.KeyR:
-		angle_inc_y -= 0.05
-		break
+//
+// Change Sphere Resolution
+// Adds latitudes and longitudes to the sphere (or removes them), turning the automatic level of detail off
+//
+// @param step (int) the number of latitudes and longitudes to add (negative to remove them)
+//
+func changeSphereResolution(step int) {
+	autoLOD = false
 
-	case glfw.KeyT:
-		angle_inc_z -= 0.05
-		break
+	lats, longs := sphere.Resolution()
+	lats = uint32(clampResolution(int(lats) + step))
+	longs = uint32(clampResolution(int(longs) + step))
 
-	case glfw.KeyY:
-		angle_inc_z += 0.05
-		break
+	if sphere.SetResolution(lats, longs) {
+		fmt.Printf("Sphere resolution: %dx%d \n", lats, longs)
+	}
+}
 
-	case glfw.KeyA:
-		scale += 0.02
-		break
+// Keeps a sphere resolution between the minimum MakeVBO can make and 128
+func clampResolution(resolution int) int {
+	if resolution < int(objects.SPHERE_MIN_LATS) {
+		return int(objects.SPHERE_MIN_LATS)
+	}
+	if resolution > 128 {
+		return 128
+	}
+	return resolution
+}
+
+//
+// Draw Light Markers
+// Draws a small sphere in the colour of each enabled point light and spotlight, the active light's is bigger
+//
+// @param View (mgl32.Mat4) the view matrix
+//
+func drawLightMarkers(View mgl32.Mat4) {
+	// The markers use the colour of their material, not the vertex colours
+	shaderProgram.SetUint("colourmode", uint32(objects.COLOR_SOLID))
 
-	case glfw.KeyS:
-		scale -= 0.02
-		break
+	for i, light := range lights {
+		if !light.Enabled || light.Type == objects.LIGHT_DIRECTIONAL {
+			continue
+		}
 
-	case glfw.KeyZ:
-		x -= 0.05
-		break
+		var size float32 = 0.03
+		if i == activeLight {
+			size = 0.05
+		}
 
-	case glfw.KeyX:
-		x += 0.05
-		break
+		var Model mgl32.Mat4 = mgl32.Translate3D(light.Position.X(), light.Position.Y(), light.Position.Z()).Mul4(mgl32.Scale3D(size, size, size))
+		var normalMatrix mgl32.Mat3 = objects.NormalMatrix(Model, View)
 
-	case glfw.KeyC:
-		y -= 0.05
-		break
+		lightMarker.GetMaterial().Emissive = light.Diffuse
+		shaderProgram.SetMat4("model", Model)
+		shaderProgram.SetMat3("normalmatrix", normalMatrix)
+		materialUniforms.Upload(lightMarker.GetMaterial(), false)
+		lightMarker.Draw()
+	}
 
-	case glfw.KeyV:
-		y += 0.05
-		break
+	shaderProgram.SetUint("colourmode", uint32(colourmode))
+}
 
-	case glfw.KeyB:
-		z -= 0.05
-		break
+//
+// Update
+// This function gets called on a fixed timestep, independent of the frame rate.
+//
+// @param glw (*wrapper.Glw) the window wrapper
+// @param dt (float64) the seconds passed since the last update
+//
+func update(glw *wrapper.Glw, dt float64) {
+	/* Trigger the actions of the keys that are held */
+	if input != nil {
+		input.Update()
+	}
 
-	case glfw.KeyN:
-		z += 0.05
-		break
+	/* Animate the scene */
+	world.Update(float32(dt))
+}
 
-	case glfw.KeyM:
-		if colourmode == 1 {
-			colourmode = 0
+//
+// Register Actions
+// Adds the actions the keys can be bound to (the keys are loaded from bindings.json)
+//
+// @param input (*wrapper.InputMap) the input map
+//
+func registerActions(input *wrapper.InputMap) {
+	input.AddAction("quit", "Close the app", wrapper.ACTION_PRESS, func() {
+		glwrapper.GetWindow().SetShouldClose(true)
+	})
+
+	input.AddAction("help", "Print the key bindings", wrapper.ACTION_PRESS, func() {
+		fmt.Print(input.Help())
+	})
+
+	// Rotation speed around each axis (clockwise is positive)
+	input.AddAction("rotate_x_inc", "Spin faster around the x axis", wrapper.ACTION_PRESS, func() {
+		spinObjects(mgl32.Vec3{-3.0, 0, 0})
+	})
+
+	input.AddAction("rotate_x_dec", "Spin slower around the x axis", wrapper.ACTION_PRESS, func() {
+		spinObjects(mgl32.Vec3{3.0, 0, 0})
+	})
+
+	input.AddAction("rotate_y_inc", "Spin faster around the y axis", wrapper.ACTION_PRESS, func() {
+		spinObjects(mgl32.Vec3{0, -3.0, 0})
+	})
+
+	input.AddAction("rotate_y_dec", "Spin slower around the y axis", wrapper.ACTION_PRESS, func() {
+		spinObjects(mgl32.Vec3{0, 3.0, 0})
+	})
+
+	input.AddAction("rotate_z_dec", "Spin slower around the z axis", wrapper.ACTION_PRESS, func() {
+		spinObjects(mgl32.Vec3{0, 0, 3.0})
+	})
+
+	input.AddAction("rotate_z_inc", "Spin faster around the z axis", wrapper.ACTION_PRESS, func() {
+		spinObjects(mgl32.Vec3{0, 0, -3.0})
+	})
+
+	// Scale both objects, the sphere stays a third of the size of the cube
+	input.AddAction("scale_up", "Make the objects bigger", wrapper.ACTION_PRESS, func() {
+		cubeNode.Scale = cubeNode.Scale.Add(mgl32.Vec3{0.02, 0.02, 0.02})
+		sphereNode.Scale = sphereNode.Scale.Add(mgl32.Vec3{0.02 / 3.0, 0.02 / 3.0, 0.02 / 3.0})
+	})
+
+	input.AddAction("scale_down", "Make the objects smaller", wrapper.ACTION_PRESS, func() {
+		cubeNode.Scale = cubeNode.Scale.Sub(mgl32.Vec3{0.02, 0.02, 0.02})
+		sphereNode.Scale = sphereNode.Scale.Sub(mgl32.Vec3{0.02 / 3.0, 0.02 / 3.0, 0.02 / 3.0})
+	})
+
+	// Move the objects closer or further apart
+	input.AddAction("move_closer", "Move the objects closer together", wrapper.ACTION_PRESS, func() {
+		cubeNode.Position[0] -= 0.05
+		sphereNode.Position[0] += 0.05
+	})
+
+	input.AddAction("move_apart", "Move the objects further apart", wrapper.ACTION_PRESS, func() {
+		cubeNode.Position[0] += 0.05
+		sphereNode.Position[0] -= 0.05
+	})
+
+	// Move the cube up/down and forward/backward
+	input.AddAction("move_cube_down", "Move the cube down", wrapper.ACTION_PRESS, func() {
+		cubeNode.Position[1] -= 0.05
+	})
+
+	input.AddAction("move_cube_up", "Move the cube up", wrapper.ACTION_PRESS, func() {
+		cubeNode.Position[1] += 0.05
+	})
+
+	input.AddAction("move_cube_back", "Move the cube away from the camera", wrapper.ACTION_PRESS, func() {
+		cubeNode.Position[2] -= 0.05
+	})
+
+	input.AddAction("move_cube_forward", "Move the cube towards the camera", wrapper.ACTION_PRESS, func() {
+		cubeNode.Position[2] += 0.05
+	})
+
+	input.AddAction("toggle_colour_mode", "Switch between the vertex colours and the colours of the materials", wrapper.ACTION_PRESS, func() {
+		if colourmode == objects.COLOR_PER_SIDE {
+			colourmode = objects.COLOR_SOLID
 		} else {
-			colourmode = 1
+			colourmode = objects.COLOR_PER_SIDE
 		}
 		fmt.Printf("Colour Mode: %s \n", colourmode)
-		break
+	})
 
-	// Cycle between drawing vertices, mesh and filled polygons
-	case glfw.KeyK:
-		sphere.DrawMode ++;
-		if sphere.DrawMode > 2 {
-			sphere.DrawMode = 0
+	input.AddAction("toggle_stats", "Show or hide the frame statistics", wrapper.ACTION_PRESS, func() {
+		glwrapper.ToggleStats()
+	})
+
+	input.AddAction("toggle_shade_mode", "Switch between per-vertex and per-fragment lighting", wrapper.ACTION_PRESS, func() {
+		if shademode == objects.SHADE_PER_VERTEX {
+			shademode = objects.SHADE_PER_FRAGMENT
+		} else {
+			shademode = objects.SHADE_PER_VERTEX
 		}
-		break
+		fmt.Printf("Shade Mode: %s \n", shademode)
+	})
 
-	case glfw.KeyL:
-		cube.DrawMode ++;
-		if cube.DrawMode > 2 {
-			cube.DrawMode = 0
+	input.AddAction("toggle_specular_mode", "Switch between the Phong and Blinn-Phong specular models", wrapper.ACTION_PRESS, func() {
+		if specularmode == objects.SPECULAR_PHONG {
+			specularmode = objects.SPECULAR_BLINN_PHONG
+		} else {
+			specularmode = objects.SPECULAR_PHONG
 		}
-	}
+		fmt.Printf("Specular Mode: %s \n", specularmode)
+	})
+
+	// Show the textures of the materials or only their colours
+	input.AddAction("toggle_texture", "Show or hide the texture", wrapper.ACTION_PRESS, func() {
+		usetexture = !usetexture
+		fmt.Printf("Texture: %t \n", usetexture)
+	})
+
+	// Lights
+	input.AddAction("cycle_light", "Make the next light the active one", wrapper.ACTION_PRESS, func() {
+		activeLight = (activeLight + 1) % len(lights)
+		fmt.Printf("Active light: %d (%s) \n", activeLight, lights[activeLight].Type)
+	})
+
+	input.AddAction("toggle_light", "Turn the active light on or off", wrapper.ACTION_PRESS, func() {
+		lights[activeLight].Enabled = !lights[activeLight].Enabled
+		fmt.Printf("Light %d enabled: %t \n", activeLight, lights[activeLight].Enabled)
+	})
+
+	input.AddAction("move_light_left", "Move the active light left", wrapper.ACTION_HOLD, func() {
+		moveActiveLight(mgl32.Vec3{-1, 0, 0})
+	})
+
+	input.AddAction("move_light_right", "Move the active light right", wrapper.ACTION_HOLD, func() {
+		moveActiveLight(mgl32.Vec3{1, 0, 0})
+	})
+
+	input.AddAction("move_light_up", "Move the active light up", wrapper.ACTION_HOLD, func() {
+		moveActiveLight(mgl32.Vec3{0, 1, 0})
+	})
+
+	input.AddAction("move_light_down", "Move the active light down", wrapper.ACTION_HOLD, func() {
+		moveActiveLight(mgl32.Vec3{0, -1, 0})
+	})
+
+	input.AddAction("move_light_back", "Move the active light away from the camera", wrapper.ACTION_HOLD, func() {
+		moveActiveLight(mgl32.Vec3{0, 0, -1})
+	})
+
+	input.AddAction("move_light_forward", "Move the active light towards the camera", wrapper.ACTION_HOLD, func() {
+		moveActiveLight(mgl32.Vec3{0, 0, 1})
+	})
+
+	input.AddAction("cycle_sphere_material", "Change the material of the sphere", wrapper.ACTION_PRESS, func() {
+		names := objects.MaterialNames(materials)
+		next := 0
+		for i, name := range names {
+			if materials[name] == sphere.GetMaterial() {
+				next = (i + 1) % len(names)
+			}
+		}
+
+		sphere.SetMaterial(materials[names[next]])
+		fmt.Printf("Sphere material: %s \n", names[next])
+	})
+
+	// Cycle between drawing vertices, mesh and filled polygons
+	input.AddAction("cycle_sphere_draw_mode", "Draw the sphere as points, lines or polygons", wrapper.ACTION_PRESS, func() {
+		sphere.DrawMode = sphere.DrawMode.Next()
+		fmt.Printf("Sphere: %s \n", sphere.DrawMode)
+	})
+
+	input.AddAction("sphere_detail_up", "Add latitudes and longitudes to the sphere", wrapper.ACTION_PRESS, func() {
+		changeSphereResolution(4)
+	})
+
+	input.AddAction("sphere_detail_down", "Remove latitudes and longitudes from the sphere", wrapper.ACTION_PRESS, func() {
+		changeSphereResolution(-4)
+	})
+
+	input.AddAction("toggle_sphere_lod", "Pick the resolution of the sphere from its size on screen", wrapper.ACTION_PRESS, func() {
+		autoLOD = !autoLOD
+		fmt.Printf("Sphere LOD: %t \n", autoLOD)
+	})
+
+	input.AddAction("cycle_cube_draw_mode", "Draw the cube as points, lines or polygons", wrapper.ACTION_PRESS, func() {
+		cube.DrawMode = cube.DrawMode.Next()
+		fmt.Printf("Cube: %s \n", cube.DrawMode)
+	})
+
+	// Camera
+	input.AddAction("toggle_camera_mode", "Switch the camera between orbit and fly modes", wrapper.ACTION_PRESS, func() {
+		viewCamera.ToggleMode()
+		fmt.Printf("Camera: %s \n", viewCamera.Mode)
+	})
+
+	input.AddAction("toggle_projection", "Switch between perspective and orthographic projection", wrapper.ACTION_PRESS, func() {
+		// Objects at the camera's target keep their size when switching
+		projection.MatchPerspective(viewCamera.Distance)
+		projection.ToggleMode()
+		fmt.Printf("Projection: %s \n", projection.Mode)
+	})
+
+	input.AddAction("reset_camera", "Move the camera back to its initial position", wrapper.ACTION_PRESS, func() {
+		viewCamera = camera.NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4.0)
+		viewCamera.SetViewport(glwrapper.Width, glwrapper.Height)
+	})
+
+	input.AddAction("camera_forward", "Fly forward (fly mode)", wrapper.ACTION_HOLD, func() {
+		viewCamera.Move(1, 0, 0, float32(glwrapper.GetTimestep()))
+	})
+
+	input.AddAction("camera_back", "Fly back (fly mode)", wrapper.ACTION_HOLD, func() {
+		viewCamera.Move(-1, 0, 0, float32(glwrapper.GetTimestep()))
+	})
+
+	input.AddAction("camera_left", "Fly left (fly mode)", wrapper.ACTION_HOLD, func() {
+		viewCamera.Move(0, -1, 0, float32(glwrapper.GetTimestep()))
+	})
+
+	input.AddAction("camera_right", "Fly right (fly mode)", wrapper.ACTION_HOLD, func() {
+		viewCamera.Move(0, 1, 0, float32(glwrapper.GetTimestep()))
+	})
+
+	input.AddAction("camera_up", "Fly up (fly mode)", wrapper.ACTION_HOLD, func() {
+		viewCamera.Move(0, 0, 1, float32(glwrapper.GetTimestep()))
+	})
+
+	input.AddAction("camera_down", "Fly down (fly mode)", wrapper.ACTION_HOLD, func() {
+		viewCamera.Move(0, 0, -1, float32(glwrapper.GetTimestep()))
+	})
+}
+
+//
+// Spin Objects
+// Changes the rotation speed of the cube and the sphere
+//
+// @param amount (mgl32.Vec3) the rotation speed added around each axis (radians per second)
+//
+func spinObjects(amount mgl32.Vec3) {
+	cubeNode.Spin = cubeNode.Spin.Add(amount)
+	sphereNode.Spin = sphereNode.Spin.Add(amount)
+}
+
+//
+// Move Active Light
+// Moves the active light for one update (directional lights turn, as their position is a direction)
+//
+// @param direction (mgl32.Vec3) the direction to move in
+//
+func moveActiveLight(direction mgl32.Vec3) {
+	// Units per second
+	const lightSpeed = 1.5
+
+	amount := direction.Mul(lightSpeed * float32(glwrapper.GetTimestep()))
+	lights[activeLight].Translate(amount.X(), amount.Y(), amount.Z())
 }
 
 //
@@ -448,5 +861,56 @@ func keyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Ac
 //
 func reshape(window *glfw.Window, width, height int) {
 	gl.Viewport(0, 0, int32(width), int32(height));
-	aspect_ratio = (float32(width) / 640.0 * 4.0) / (float32(height) / 480.0 * 3.0);
+	projection.SetViewport(width, height)
+
+	// The cursor positions are in window coordinates, not framebuffer pixels
+	viewCamera.SetViewport(window.GetSize())
+}
+
+//
+// Mouse Button
+// This gets called when a mouse button is pressed or released, dragging with it moves the camera
+//
+// @param window (*glfw.Window) a pointer to the window
+// @param button (glfw.MouseButton) the button
+// @param action (glfw.Action) the state of the button
+// @param mods (glfw.ModifierKey) the pressed modified keys.
+//
+func mouseButton(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	if action == glfw.Release {
+		viewCamera.EndDrag()
+		return
+	}
+
+	x, y := window.GetCursorPos()
+	switch button {
+	case glfw.MouseButtonLeft:
+		viewCamera.BeginDrag(camera.BUTTON_ROTATE, x, y)
+	case glfw.MouseButtonRight, glfw.MouseButtonMiddle:
+		viewCamera.BeginDrag(camera.BUTTON_PAN, x, y)
+	}
+}
+
+//
+// Cursor Position
+// This gets called when the cursor moves
+//
+// @param window (*glfw.Window) a pointer to the window
+// @param x (float64) the horizontal position of the cursor (from the left of the window)
+// @param y (float64) the vertical position of the cursor (from the top of the window)
+//
+func cursorPos(window *glfw.Window, x, y float64) {
+	viewCamera.Drag(x, y)
+}
+
+//
+// Scroll
+// This gets called when the scroll wheel moves, it zooms the camera
+//
+// @param window (*glfw.Window) a pointer to the window
+// @param xoff (float64) the horizontal scroll
+// @param yoff (float64) the vertical scroll (positive when scrolling up)
+//
+func scroll(window *glfw.Window, xoff, yoff float64) {
+	viewCamera.Scroll(float32(yoff))
 }
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"./imaging"
+	"./objects"
+	"./camera"
+	"./wrapper"
+	"./gpu"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Golden image tests: each scene is rendered offscreen and compared with a checked-in PNG in testdata/golden.
+// Run `go test -run TestGoldenScenes -update` to (re)create the golden images after an intended visual change.
+// A scene without a golden image fails, the tests are only skipped on machines where no GL context can be created.
+
+var updateGolden = flag.Bool("update", false, "write the rendered scenes as the new golden images")
+var goldenTolerance = flag.Int("tolerance", 8, "largest difference allowed in each colour channel of a pixel")
+
+const goldenWidth = 320
+const goldenHeight = 240
+
+// Named scenes, each one changes the state set by InitApp before the frame is rendered
+var goldenScenes = []struct {
+	name  string
+	setup func()
+}{
+	{"cube_and_sphere", func() {}},
+
+	{"rotated", func() {
+		cubeNode.Rotation = mgl32.Vec3{-0.6, -0.8, 0.2}
+		sphereNode.Rotation = mgl32.Vec3{-0.6, -0.8, 0.2}
+	}},
+
+	// Wireframes show the sphere's indices, so broken strips or fans change the image
+	{"wireframe", func() {
+		cube.DrawMode = objects.DRAW_LINES
+		sphere.DrawMode = objects.DRAW_LINES
+	}},
+
+	{"points", func() {
+		cube.DrawMode = objects.DRAW_POINTS
+		sphere.DrawMode = objects.DRAW_POINTS
+	}},
+
+	{"per_vertex_phong", func() {
+		shademode = objects.SHADE_PER_VERTEX
+		specularmode = objects.SPECULAR_PHONG
+	}},
+
+	// The texture shows the sphere's seam and the orientation of the cube's faces
+	{"textured", func() {
+		usetexture = true
+	}},
+
+	{"orthographic", func() {
+		projection.MatchPerspective(viewCamera.Distance)
+		projection.Mode = camera.PROJECTION_ORTHOGRAPHIC
+	}},
+}
+
+// Functions that have to run on the main thread (OpenGL and the window system are not thread safe)
+var mainThread = make(chan func())
+
+// Runs the tests in a goroutine, while the main goroutine (locked to the main thread in init) runs the GL calls
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	// Renders with the assets embedded in the binary, like the app does
+	gpu.SetAssets(embeddedAssets)
+
+	done := make(chan int)
+	go func() {
+		done <- m.Run()
+	}()
+
+	for {
+		select {
+		case function := <-mainThread:
+			function()
+		case code := <-done:
+			os.Exit(code)
+		}
+	}
+}
+
+// Runs a function on the main thread and waits for it to finish, returning the panic (if any) as an error
+func onMainThread(function func()) (err error) {
+	finished := make(chan struct{})
+
+	mainThread <- func() {
+		defer close(finished)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("%v", recovered)
+			}
+		}()
+
+		function()
+	}
+
+	<-finished
+	return err
+}
+
+func TestGoldenScenes(t *testing.T) {
+	for _, scene := range goldenScenes {
+		scene := scene
+		t.Run(scene.name, func(t *testing.T) {
+			got := renderGoldenScene(t, scene.setup)
+			checkGolden(t, scene.name, got)
+		})
+	}
+}
+
+// Renders one frame of the app offscreen after applying the setup of a scene
+func renderGoldenScene(t *testing.T, setup func()) *image.RGBA {
+	glw := wrapper.NewWrapper(goldenWidth, goldenHeight, "Golden Image")
+	glw.SetOffscreen(1, "")
+
+	// Without a display or a GL driver there is nothing to compare
+	var contextErr error
+	if err := onMainThread(func() { _, contextErr = glw.CreateWindow() }); err != nil {
+		t.Fatalf("creating the window panicked: %v", err)
+	}
+	if contextErr != nil {
+		t.Skipf("can't create an offscreen GL context: %v", contextErr)
+	}
+
+	var frame *image.RGBA
+	err := onMainThread(func() {
+		defer glw.Terminate()
+
+		glw.SetRenderCallback(drawLoop)
+		if err := InitApp(glw); err != nil {
+			panic(err)
+		}
+		setup()
+
+		// The projection has to use the size of the offscreen framebuffer, not a fixed aspect ratio
+		if aspect := projection.Aspect(); aspect != float32(goldenWidth) / float32(goldenHeight) {
+			panic(fmt.Errorf("projection aspect ratio is %v, want %v", aspect, float32(goldenWidth) / float32(goldenHeight)))
+		}
+
+		frame = glw.CaptureFrame()
+	})
+
+	if err != nil {
+		t.Fatalf("failed to render the scene: %v", err)
+	}
+
+	// Terminate releases everything InitApp created
+	if leaked := gpu.LiveResources(); len(leaked) > 0 {
+		t.Errorf("%d GPU resources were not released: %v", len(leaked), leaked)
+	}
+
+	return frame
+}
+
+// Compares a frame with its golden image, saving the frame and a diff image in testdata/failures if they differ
+func checkGolden(t *testing.T, name string, got *image.RGBA) {
+	goldenPath := filepath.Join("testdata", "golden", name + ".png")
+
+	if *updateGolden {
+		if err := imaging.SavePNG(goldenPath, got); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("updated %s", goldenPath)
+		return
+	}
+
+	want, err := imaging.LoadPNG(goldenPath)
+	if os.IsNotExist(err) {
+		t.Fatalf("no golden image at %s, run `go test -run TestGoldenScenes -update` to create it", goldenPath)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comparison, err := imaging.Compare(got, want, uint8(*goldenTolerance))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if comparison.Mismatched == 0 {
+		return
+	}
+
+	gotPath := filepath.Join("testdata", "failures", name + "_got.png")
+	diffPath := filepath.Join("testdata", "failures", name + "_diff.png")
+	if err := imaging.SavePNG(gotPath, got); err != nil {
+		t.Error(err)
+	}
+	if err := imaging.SavePNG(diffPath, comparison.Diff); err != nil {
+		t.Error(err)
+	}
+
+	t.Errorf("%d of %d pixels differ by more than %d (max difference %d), see %s and %s",
+		comparison.Mismatched, comparison.Total, *goldenTolerance, comparison.MaxDifference, gotPath, diffPath)
+}
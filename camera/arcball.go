@@ -0,0 +1,43 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Arcball rotation (Shoemake): the viewport is treated as a ball, dragging a point on it rotates the ball.
+
+// Maps a point of the viewport (in pixels, origin at the top left) onto the unit arcball in camera space.
+// Points outside the ball are mapped to its silhouette.
+func ArcballVector(x, y float64, width, height int) mgl32.Vec3 {
+	// Normalized to -1..1 with y up, using the smallest dimension so the ball is round
+	radius := math.Min(float64(width), float64(height)) / 2.0
+	if radius <= 0 {
+		return mgl32.Vec3{0, 0, 1}
+	}
+
+	px := float32((x - float64(width) / 2.0) / radius)
+	py := float32((float64(height) / 2.0 - y) / radius)
+
+	lengthSquared := px * px + py * py
+	if lengthSquared > 1.0 {
+		length := float32(math.Sqrt(float64(lengthSquared)))
+		return mgl32.Vec3{px / length, py / length, 0}
+	}
+
+	return mgl32.Vec3{px, py, float32(math.Sqrt(float64(1.0 - lengthSquared)))}
+}
+
+// Returns the rotation that takes one arcball vector to another (the identity if they are the same)
+func ArcballRotation(from, to mgl32.Vec3) mgl32.Quat {
+	axis := from.Cross(to)
+	if axis.Len() < 1e-6 {
+		return mgl32.QuatIdent()
+	}
+
+	cos := mgl32.Clamp(from.Dot(to), -1.0, 1.0)
+	angle := float32(math.Acos(float64(cos)))
+
+	return mgl32.QuatRotate(angle, axis.Normalize())
+}
@@ -0,0 +1,249 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type Mode int32
+type Button int32
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	MODE_ORBIT Mode = 0 + iota // Rotates around a target with an arcball
+	MODE_FLY // First person, looks around with yaw and pitch
+)
+
+const (
+	BUTTON_NONE Button = iota
+	BUTTON_ROTATE // Drags rotate the camera (arcball in orbit mode, look around in fly mode)
+	BUTTON_PAN // Drags move the camera parallel to the view plane
+)
+
+// Pitch is kept just under 90 degrees, looking straight up or down makes the view direction parallel to up
+const maxPitch = 89.0 * math.Pi / 180.0
+
+var modeNames = [...]string{
+	"_",
+	"Orbit",
+	"Fly",
+}
+
+// Camera controlled with the mouse, with orbit (arcball) and first person (fly) modes
+type Camera struct {
+	Mode                   Mode
+
+	// Orbit mode
+	Target                 mgl32.Vec3 // Point the camera rotates around
+	Distance               float32    // Distance from the target
+	Orientation            mgl32.Quat // Rotation of the camera around the target
+	MinDistance, MaxDistance float32
+
+	// Fly mode
+	Position               mgl32.Vec3
+	Yaw, Pitch             float32 // Radians, a yaw of 0 looks down the -z axis
+
+	// Speeds
+	LookSpeed              float32 // Radians per pixel dragged in fly mode
+	ZoomSpeed              float32 // Fraction of the distance zoomed per scroll step
+	MoveSpeed              float32 // Units per second moved in fly mode
+
+	// Viewport size (in pixels), used to map the cursor onto the arcball
+	width, height          int
+
+	// Drag state
+	dragging               Button
+	lastX, lastY           float64
+}
+
+// Creates a camera orbiting around a target, looking at it from the +z axis
+func NewOrbitCamera(target mgl32.Vec3, distance float32) *Camera {
+	return &Camera{
+		MODE_ORBIT, // mode
+		target, distance, mgl32.QuatIdent(), // target, distance, orientation
+		0.5, 50.0, // min distance, max distance
+		target.Add(mgl32.Vec3{0, 0, distance}), 0, 0, // position, yaw, pitch
+		0.005, 0.1, 2.0, // look, zoom and move speed
+		1, 1, // width, height
+		BUTTON_NONE, 0, 0, // dragging, last x, last y
+	}
+}
+
+// Returns the view matrix of the camera
+func (camera *Camera) View() mgl32.Mat4 {
+	return mgl32.LookAtV(camera.Eye(), camera.Eye().Add(camera.Forward()), camera.Up())
+}
+
+// Returns the position of the camera in world space
+func (camera *Camera) Eye() mgl32.Vec3 {
+	if camera.Mode == MODE_FLY {
+		return camera.Position
+	}
+
+	return camera.Target.Add(camera.Orientation.Rotate(mgl32.Vec3{0, 0, camera.Distance}))
+}
+
+// Returns the direction the camera looks at (unit length)
+func (camera *Camera) Forward() mgl32.Vec3 {
+	if camera.Mode == MODE_FLY {
+		cosPitch := float32(math.Cos(float64(camera.Pitch)))
+		return mgl32.Vec3{
+			cosPitch * float32(math.Sin(float64(camera.Yaw))),
+			float32(math.Sin(float64(camera.Pitch))),
+			-cosPitch * float32(math.Cos(float64(camera.Yaw))),
+		}
+	}
+
+	return camera.Orientation.Rotate(mgl32.Vec3{0, 0, -1})
+}
+
+// Returns the up direction of the camera (unit length)
+func (camera *Camera) Up() mgl32.Vec3 {
+	if camera.Mode == MODE_FLY {
+		return camera.Right().Cross(camera.Forward()).Normalize()
+	}
+
+	return camera.Orientation.Rotate(mgl32.Vec3{0, 1, 0})
+}
+
+// Returns the right direction of the camera (unit length)
+func (camera *Camera) Right() mgl32.Vec3 {
+	if camera.Mode == MODE_FLY {
+		// Fly cameras stay level, so right is always horizontal
+		return mgl32.Vec3{float32(math.Cos(float64(camera.Yaw))), 0, float32(math.Sin(float64(camera.Yaw)))}
+	}
+
+	return camera.Orientation.Rotate(mgl32.Vec3{1, 0, 0})
+}
+
+// Sets the size of the viewport the cursor positions are relative to
+func (camera *Camera) SetViewport(width, height int) {
+	camera.width, camera.height = width, height
+}
+
+// Switches between orbit and fly modes, keeping the camera where it is and looking in the same direction
+func (camera *Camera) SetMode(mode Mode) {
+	if mode == camera.Mode {
+		return
+	}
+
+	eye, forward := camera.Eye(), camera.Forward()
+
+	if mode == MODE_FLY {
+		camera.Position = eye
+		camera.Yaw = float32(math.Atan2(float64(forward[0]), float64(-forward[2])))
+		camera.Pitch = float32(math.Asin(float64(mgl32.Clamp(forward[1], -1, 1))))
+		camera.clampPitch()
+	} else {
+		// Orbits around the point in front of the camera, at the current orbit distance
+		camera.Target = eye.Add(forward.Mul(camera.Distance))
+		// The orientation takes the camera axes (right, up and back) to world space
+		rotation := mgl32.Mat3FromCols(camera.Right(), camera.Up(), forward.Mul(-1))
+		camera.Orientation = mgl32.Mat4ToQuat(rotation.Mat4()).Normalize()
+	}
+
+	camera.Mode = mode
+}
+
+// Switches to the other mode
+func (camera *Camera) ToggleMode() {
+	if camera.Mode == MODE_ORBIT {
+		camera.SetMode(MODE_FLY)
+	} else {
+		camera.SetMode(MODE_ORBIT)
+	}
+}
+
+// Starts dragging with a button at a cursor position (in pixels)
+func (camera *Camera) BeginDrag(button Button, x, y float64) {
+	camera.dragging = button
+	camera.lastX, camera.lastY = x, y
+}
+
+// Moves the cursor while dragging, rotating or panning the camera
+func (camera *Camera) Drag(x, y float64) {
+	switch camera.dragging {
+	case BUTTON_ROTATE:
+		if camera.Mode == MODE_FLY {
+			camera.Look(float32(x - camera.lastX), float32(y - camera.lastY))
+		} else {
+			camera.arcball(camera.lastX, camera.lastY, x, y)
+		}
+	case BUTTON_PAN:
+		camera.Pan(float32(x - camera.lastX), float32(y - camera.lastY))
+	}
+
+	camera.lastX, camera.lastY = x, y
+}
+
+// Stops dragging
+func (camera *Camera) EndDrag() {
+	camera.dragging = BUTTON_NONE
+}
+
+func (camera *Camera) IsDragging() bool {
+	return camera.dragging != BUTTON_NONE
+}
+
+// Turns a fly camera by a cursor movement in pixels (moving right turns right, moving down looks down)
+func (camera *Camera) Look(dx, dy float32) {
+	camera.Yaw += dx * camera.LookSpeed
+	camera.Pitch -= dy * camera.LookSpeed
+	camera.clampPitch()
+}
+
+// Moves the camera parallel to the view plane by a cursor movement in pixels, so the scene follows the cursor
+func (camera *Camera) Pan(dx, dy float32) {
+	// Scaled by the distance, so the point at the target moves with the cursor at any zoom
+	scale := camera.Distance * 2.0 / float32(camera.height)
+	offset := camera.Right().Mul(-dx * scale).Add(camera.Up().Mul(dy * scale))
+
+	if camera.Mode == MODE_FLY {
+		camera.Position = camera.Position.Add(offset)
+	} else {
+		camera.Target = camera.Target.Add(offset)
+	}
+}
+
+// Zooms in (positive) or out (negative) by scroll steps
+func (camera *Camera) Scroll(steps float32) {
+	if camera.Mode == MODE_FLY {
+		camera.Position = camera.Position.Add(camera.Forward().Mul(steps * camera.ZoomSpeed * camera.Distance))
+		return
+	}
+
+	camera.Distance *= float32(math.Pow(float64(1.0 - camera.ZoomSpeed), float64(steps)))
+	camera.Distance = mgl32.Clamp(camera.Distance, camera.MinDistance, camera.MaxDistance)
+}
+
+// Moves a fly camera along its forward, right and up directions for the given number of seconds
+func (camera *Camera) Move(forward, right, up, seconds float32) {
+	if camera.Mode != MODE_FLY {
+		return
+	}
+
+	speed := camera.MoveSpeed * seconds
+	camera.Position = camera.Position.
+		Add(camera.Forward().Mul(forward * speed)).
+		Add(camera.Right().Mul(right * speed)).
+		Add(mgl32.Vec3{0, up * speed, 0})
+}
+
+func (mode Mode) String() string {
+	return modeNames[mode]
+}
+
+// Rotates the orbit camera with the arcball rotation between two cursor positions
+func (camera *Camera) arcball(fromX, fromY, toX, toY float64) {
+	from := ArcballVector(fromX, fromY, camera.width, camera.height)
+	to := ArcballVector(toX, toY, camera.width, camera.height)
+
+	// The rotation is in camera space and turns the scene, so the camera turns the opposite way
+	rotation := ArcballRotation(from, to)
+	camera.Orientation = camera.Orientation.Mul(rotation.Inverse()).Normalize()
+}
+
+func (camera *Camera) clampPitch() {
+	camera.Pitch = mgl32.Clamp(camera.Pitch, -maxPitch, maxPitch)
+}
@@ -0,0 +1,179 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const epsilon = 1e-4
+
+func assertVec3(t *testing.T, name string, got, want mgl32.Vec3) {
+	t.Helper()
+	if got.Sub(want).Len() > epsilon {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestArcballVector(t *testing.T) {
+	// The centre of the viewport is the front of the ball
+	assertVec3(t, "centre", ArcballVector(200, 100, 400, 200), mgl32.Vec3{0, 0, 1})
+
+	// The ball fits the smallest dimension, its right edge is 100 pixels from the centre
+	assertVec3(t, "right edge", ArcballVector(300, 100, 400, 200), mgl32.Vec3{1, 0, 0})
+
+	// Screen y grows downwards, ball y grows upwards
+	assertVec3(t, "top edge", ArcballVector(200, 0, 400, 200), mgl32.Vec3{0, 1, 0})
+
+	// Points outside the ball are mapped to its silhouette
+	assertVec3(t, "outside", ArcballVector(400, 100, 400, 200), mgl32.Vec3{1, 0, 0})
+
+	// Every point is on the unit ball
+	for _, point := range [][2]float64{{0, 0}, {120, 40}, {250, 160}, {399, 199}} {
+		if length := ArcballVector(point[0], point[1], 400, 200).Len(); math.Abs(float64(length) - 1) > epsilon {
+			t.Errorf("ArcballVector(%v) has length %v, want 1", point, length)
+		}
+	}
+}
+
+func TestArcballRotation(t *testing.T) {
+	from := mgl32.Vec3{0, 0, 1}
+	to := mgl32.Vec3{1, 0, 0}
+
+	rotation := ArcballRotation(from, to)
+	assertVec3(t, "rotated", rotation.Rotate(from), to)
+
+	identity := ArcballRotation(from, from)
+	if !identity.ApproxEqualThreshold(mgl32.QuatIdent(), epsilon) {
+		t.Errorf("rotation between equal vectors = %v, want the identity", identity)
+	}
+}
+
+func TestOrbitCameraView(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{1, 2, 3}, 4)
+
+	assertVec3(t, "eye", camera.Eye(), mgl32.Vec3{1, 2, 7})
+
+	// The same view as the fixed camera it replaces
+	want := mgl32.LookAtV(mgl32.Vec3{1, 2, 7}, mgl32.Vec3{1, 2, 3}, mgl32.Vec3{0, 1, 0})
+	if !camera.View().ApproxEqualThreshold(want, epsilon) {
+		t.Errorf("View() = %v, want %v", camera.View(), want)
+	}
+
+	// The target is in front of the camera, on the -z axis of eye space
+	target := camera.View().Mul4x1(mgl32.Vec4{1, 2, 3, 1})
+	assertVec3(t, "target in eye space", target.Vec3(), mgl32.Vec3{0, 0, -4})
+}
+
+func TestOrbitCameraDrag(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4)
+	camera.SetViewport(400, 400)
+
+	// Dragging from the centre to the right edge turns the scene a quarter turn to the right,
+	// so the camera ends up on the -x side looking at the target
+	camera.BeginDrag(BUTTON_ROTATE, 200, 200)
+	camera.Drag(400, 200)
+	camera.EndDrag()
+
+	assertVec3(t, "eye", camera.Eye(), mgl32.Vec3{-4, 0, 0})
+	assertVec3(t, "forward", camera.Forward(), mgl32.Vec3{1, 0, 0})
+	assertVec3(t, "up", camera.Up(), mgl32.Vec3{0, 1, 0})
+
+	// Moving the cursor without a button doesn't change the camera
+	camera.Drag(0, 0)
+	assertVec3(t, "eye after release", camera.Eye(), mgl32.Vec3{-4, 0, 0})
+
+	// The distance to the target is kept
+	if distance := camera.Eye().Sub(camera.Target).Len(); math.Abs(float64(distance) - 4) > epsilon {
+		t.Errorf("distance to target = %v, want 4", distance)
+	}
+}
+
+func TestPan(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4)
+	camera.SetViewport(400, 400)
+
+	// Dragging right moves the scene right, so the camera and its target move left
+	camera.BeginDrag(BUTTON_PAN, 200, 200)
+	camera.Drag(300, 200)
+
+	if camera.Target.X() >= 0 || camera.Target.Y() != 0 || camera.Target.Z() != 0 {
+		t.Errorf("target after panning = %v, want it moved along -x", camera.Target)
+	}
+	assertVec3(t, "forward", camera.Forward(), mgl32.Vec3{0, 0, -1})
+}
+
+func TestScrollClampsDistance(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4)
+
+	camera.Scroll(1)
+	if camera.Distance >= 4 {
+		t.Errorf("distance after zooming in = %v, want less than 4", camera.Distance)
+	}
+
+	camera.Scroll(1000)
+	if camera.Distance != camera.MinDistance {
+		t.Errorf("distance after zooming in a lot = %v, want %v", camera.Distance, camera.MinDistance)
+	}
+
+	camera.Scroll(-1000)
+	if camera.Distance != camera.MaxDistance {
+		t.Errorf("distance after zooming out a lot = %v, want %v", camera.Distance, camera.MaxDistance)
+	}
+}
+
+func TestFlyLookClampsPitch(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4)
+	camera.SetMode(MODE_FLY)
+
+	// Moving the cursor up a long way stops just before looking straight up
+	camera.Look(0, -100000)
+	if camera.Pitch != maxPitch {
+		t.Errorf("pitch = %v, want %v", camera.Pitch, maxPitch)
+	}
+
+	camera.Look(0, 100000)
+	if camera.Pitch != -maxPitch {
+		t.Errorf("pitch = %v, want %v", camera.Pitch, -maxPitch)
+	}
+
+	// The view matrix is still valid when looking almost straight down
+	for _, value := range camera.View() {
+		if math.IsNaN(float64(value)) {
+			t.Fatalf("View() = %v, contains NaN", camera.View())
+		}
+	}
+}
+
+func TestFlyMove(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4)
+	camera.SetMode(MODE_FLY)
+	camera.MoveSpeed = 1
+
+	// Turning right a quarter turn makes forward the +x axis
+	camera.Look(float32(math.Pi / 2) / camera.LookSpeed, 0)
+	assertVec3(t, "forward", camera.Forward(), mgl32.Vec3{1, 0, 0})
+	assertVec3(t, "right", camera.Right(), mgl32.Vec3{0, 0, 1})
+
+	camera.Move(2, 0, 0, 1)
+	assertVec3(t, "position", camera.Position, mgl32.Vec3{2, 0, 4})
+}
+
+func TestSetModeKeepsView(t *testing.T) {
+	camera := NewOrbitCamera(mgl32.Vec3{0, 0, 0}, 4)
+	camera.SetViewport(400, 400)
+	camera.BeginDrag(BUTTON_ROTATE, 200, 200)
+	camera.Drag(260, 150)
+	camera.EndDrag()
+
+	eye, forward := camera.Eye(), camera.Forward()
+
+	camera.SetMode(MODE_FLY)
+	assertVec3(t, "fly eye", camera.Eye(), eye)
+	assertVec3(t, "fly forward", camera.Forward(), forward)
+
+	camera.SetMode(MODE_ORBIT)
+	assertVec3(t, "orbit eye", camera.Eye(), eye)
+	assertVec3(t, "orbit forward", camera.Forward(), forward)
+}
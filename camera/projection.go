@@ -0,0 +1,114 @@
+package camera
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+type ProjectionMode int32
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	PROJECTION_PERSPECTIVE ProjectionMode = 0 + iota // Objects further away look smaller
+	PROJECTION_ORTHOGRAPHIC // Objects keep their size at any distance
+)
+
+var projectionModeNames = [...]string{
+	"_",
+	"Perspective",
+	"Orthographic",
+}
+
+// Projection matrix of the camera, with the aspect ratio taken from the size of the framebuffer
+type Projection struct {
+	Mode          ProjectionMode
+
+	FieldOfView   float32 // Vertical field of view of the perspective projection (degrees)
+	Near, Far     float32 // Distance to the clipping planes
+	ViewHeight    float32 // Height of the orthographic view volume (world units)
+
+	width, height int // Framebuffer size (in pixels)
+}
+
+// Creates a perspective projection, the field of view is vertical and in degrees
+func NewProjection(fieldOfView, near, far float32) *Projection {
+	return &Projection{
+		PROJECTION_PERSPECTIVE, // mode
+		fieldOfView, // field of view
+		near, far, // near, far
+		2.0, // view height
+		1, 1, // width, height
+	}
+}
+
+// Sets the size of the framebuffer (in pixels), the aspect ratio is calculated from it
+func (projection *Projection) SetViewport(width, height int) {
+	projection.width, projection.height = width, height
+}
+
+// Returns the aspect ratio (width / height) of the framebuffer, 1 if it has no size (like a minimized window)
+func (projection *Projection) Aspect() float32 {
+	if projection.width <= 0 || projection.height <= 0 {
+		return 1.0
+	}
+
+	return float32(projection.width) / float32(projection.height)
+}
+
+// Returns the projection matrix
+func (projection *Projection) Matrix() mgl32.Mat4 {
+	aspect := projection.Aspect()
+
+	if projection.Mode == PROJECTION_ORTHOGRAPHIC {
+		top := projection.ViewHeight / 2.0
+		right := top * aspect
+		return mgl32.Ortho(-right, right, -top, top, projection.Near, projection.Far)
+	}
+
+	return mgl32.Perspective(mgl32.DegToRad(projection.FieldOfView), aspect, projection.Near, projection.Far)
+}
+
+// Sets the height of the orthographic view volume so objects at a distance look the same size in both modes
+func (projection *Projection) MatchPerspective(distance float32) {
+	halfAngle := float64(mgl32.DegToRad(projection.FieldOfView)) / 2.0
+	projection.ViewHeight = 2.0 * distance * float32(math.Tan(halfAngle))
+}
+
+//
+// Screen Size
+// Calculates the diameter on screen of a sphere, to pick how detailed it has to be
+//
+// @param center (mgl32.Vec3) the centre of the sphere in eye space (transformed by the view matrix)
+// @param radius (float32) the radius of the sphere
+//
+// @return size (float32) the diameter in pixels (the height of the framebuffer if the camera is inside the sphere)
+//
+func (projection *Projection) ScreenSize(center mgl32.Vec3, radius float32) float32 {
+	if projection.Mode == PROJECTION_ORTHOGRAPHIC {
+		return 2.0 * radius / projection.ViewHeight * float32(projection.height)
+	}
+
+	// The sphere touches the cone of the view through its silhouette, sqrt(d² - r²) away from the eye
+	distance := center.Len()
+	if distance <= radius {
+		return float32(projection.height)
+	}
+
+	halfAngle := float64(mgl32.DegToRad(projection.FieldOfView)) / 2.0
+	tangent := float64(radius) / math.Sqrt(float64(distance * distance - radius * radius))
+	return float32(tangent / math.Tan(halfAngle)) * float32(projection.height)
+}
+
+// Switches between perspective and orthographic
+func (projection *Projection) ToggleMode() {
+	if projection.Mode == PROJECTION_PERSPECTIVE {
+		projection.Mode = PROJECTION_ORTHOGRAPHIC
+	} else {
+		projection.Mode = PROJECTION_PERSPECTIVE
+	}
+}
+
+func (mode ProjectionMode) String() string {
+	return projectionModeNames[mode]
+}
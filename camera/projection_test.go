@@ -0,0 +1,114 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestProjectionAspect(t *testing.T) {
+	projection := NewProjection(45, 0.1, 100)
+
+	projection.SetViewport(1024, 768)
+	if aspect := projection.Aspect(); math.Abs(float64(aspect) - 4.0 / 3.0) > epsilon {
+		t.Errorf("Aspect() = %v, want 4/3", aspect)
+	}
+
+	// High DPI framebuffers are bigger than the window, only the ratio matters
+	projection.SetViewport(2560, 1080)
+	if aspect := projection.Aspect(); math.Abs(float64(aspect) - 2560.0 / 1080.0) > epsilon {
+		t.Errorf("Aspect() = %v, want %v", aspect, 2560.0 / 1080.0)
+	}
+
+	// A minimized window has an empty framebuffer
+	projection.SetViewport(0, 0)
+	if aspect := projection.Aspect(); aspect != 1 {
+		t.Errorf("Aspect() of an empty framebuffer = %v, want 1", aspect)
+	}
+}
+
+func TestPerspectiveFieldOfViewInDegrees(t *testing.T) {
+	projection := NewProjection(90, 0.1, 100)
+	projection.SetViewport(400, 400)
+
+	// With a 90 degree field of view, a point at 45 degrees above the view direction is at the top of the screen
+	clip := projection.Matrix().Mul4x1(mgl32.Vec4{0, 5, -5, 1})
+	if y := clip.Y() / clip.W(); math.Abs(float64(y) - 1) > epsilon {
+		t.Errorf("projected y = %v, want 1", y)
+	}
+
+	want := mgl32.Perspective(mgl32.DegToRad(90), 1, 0.1, 100)
+	if !projection.Matrix().ApproxEqualThreshold(want, epsilon) {
+		t.Errorf("Matrix() = %v, want %v", projection.Matrix(), want)
+	}
+}
+
+func TestOrthographic(t *testing.T) {
+	projection := NewProjection(45, 0.1, 100)
+	projection.SetViewport(800, 400)
+	projection.ToggleMode()
+
+	if projection.Mode != PROJECTION_ORTHOGRAPHIC {
+		t.Fatalf("Mode = %v, want %v", projection.Mode, PROJECTION_ORTHOGRAPHIC)
+	}
+
+	// Sizes don't change with the distance, and the width is scaled by the aspect ratio
+	projection.ViewHeight = 4
+	for _, z := range []float32{-1, -50} {
+		corner := projection.Matrix().Mul4x1(mgl32.Vec4{4, 2, z, 1})
+		assertVec3(t, "corner", mgl32.Vec3{corner.X(), corner.Y(), 0}, mgl32.Vec3{1, 1, 0})
+	}
+
+	projection.ToggleMode()
+	if projection.Mode != PROJECTION_PERSPECTIVE {
+		t.Errorf("Mode = %v, want %v", projection.Mode, PROJECTION_PERSPECTIVE)
+	}
+}
+
+func TestMatchPerspective(t *testing.T) {
+	projection := NewProjection(45, 0.1, 100)
+	projection.SetViewport(640, 480)
+
+	// A point at the distance lands at the same place on the screen in both modes
+	projection.MatchPerspective(4)
+	point := mgl32.Vec4{0.5, 0.3, -4, 1}
+
+	perspective := projection.Matrix().Mul4x1(point)
+	projection.ToggleMode()
+	orthographic := projection.Matrix().Mul4x1(point)
+
+	assertVec3(t, "projected",
+		mgl32.Vec3{orthographic.X() / orthographic.W(), orthographic.Y() / orthographic.W(), 0},
+		mgl32.Vec3{perspective.X() / perspective.W(), perspective.Y() / perspective.W(), 0})
+}
+
+func TestScreenSize(t *testing.T) {
+	projection := NewProjection(90, 0.1, 100)
+	projection.SetViewport(800, 600)
+
+	// With a 90 degree field of view, a sphere seen at 45 degrees fills the height of the screen
+	radius := float32(math.Sqrt2 / 2)
+	if size := projection.ScreenSize(mgl32.Vec3{0, 0, -1}, radius); math.Abs(float64(size) - 600) > 1e-2 {
+		t.Errorf("ScreenSize() = %v, want 600", size)
+	}
+
+	// Further away it gets smaller, and it fills the screen if the camera is inside it
+	near := projection.ScreenSize(mgl32.Vec3{0, 0, -5}, 1)
+	far := projection.ScreenSize(mgl32.Vec3{0, 0, -10}, 1)
+	if far >= near {
+		t.Errorf("ScreenSize() at 10 = %v, want less than at 5 (%v)", far, near)
+	}
+	if size := projection.ScreenSize(mgl32.Vec3{0, 0, -0.5}, 1); size != 600 {
+		t.Errorf("ScreenSize() inside the sphere = %v, want 600", size)
+	}
+
+	// In orthographic the size doesn't depend on the distance
+	projection.ToggleMode()
+	projection.ViewHeight = 4
+	for _, z := range []float32{-1, -50} {
+		if size := projection.ScreenSize(mgl32.Vec3{0, 0, z}, 1); math.Abs(float64(size) - 300) > 1e-2 {
+			t.Errorf("orthographic ScreenSize() at %v = %v, want 300", z, size)
+		}
+	}
+}
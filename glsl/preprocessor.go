@@ -0,0 +1,258 @@
+package glsl
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Reads a source file, the preprocessor doesn't know where the files are stored
+type FileReader func(path string) (string, error)
+
+// Expands the #include directives of a shader and adds the defines set from Go after its #version line
+type Preprocessor struct {
+	Defines  map[string]string // Names and values added as #define, an empty value defines the name only
+
+	readFile FileReader
+}
+
+// Shader source ready to compile, with the files it was built from
+type Result struct {
+	Source string   // The preprocessed source
+	Files  []string // Every file used, its index is the source string number of its #line directives
+}
+
+var (
+	includeDirective = regexp.MustCompile(`^\s*#\s*include\b\s*(.*)$`)
+	includePath      = regexp.MustCompile(`^"([^"]+)"\s*(//.*)?$`)
+	versionDirective = regexp.MustCompile(`^\s*#\s*version\b`)
+
+	// Line numbers in the compiler logs of the main drivers: "0(12) : error" (NVIDIA), "0:12(5): error" (Mesa)
+	// and "ERROR: 0:12: message" (AMD, Intel and Apple). The first number is the source string number.
+	logLine = regexp.MustCompile(`^(?:(ERROR|WARNING): )?(\d+)[:(](\d+)\)?(?:\(\d+\))?\s*:\s*(.*)$`)
+)
+
+// Creates a preprocessor that reads the files with a function (ioutil.ReadFile, or an asset loader)
+func New(readFile FileReader) *Preprocessor {
+	return &Preprocessor{
+		make(map[string]string), // defines
+		readFile, // file reader
+	}
+}
+
+// Adds a define, replacing the value if it was already defined
+func (preprocessor *Preprocessor) Define(name, value string) {
+	preprocessor.Defines[name] = value
+}
+
+//
+// Process
+// Reads a shader and expands its includes. Included files are found relative to the file including them,
+// a file can be included more than once but not from itself (directly or through other includes).
+// #line directives are added around every include so the compiler reports the right line numbers,
+// with the index of the file in Result.Files as source string number (GLSL 330 doesn't take file names).
+//
+// @param path (string) the path to the shader file
+//
+// @return result (*Result) the preprocessed source and the files used
+// @return error (error) the error (if a file is missing, an include is malformed or includes are cyclic)
+//
+func (preprocessor *Preprocessor) Process(path string) (*Result, error) {
+	source, err := preprocessor.read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{"", []string{filepath.Clean(path)}}
+	var output []string
+
+	// The defines go after the #version line (which has to come first), or at the top if there isn't one
+	lines := strings.Split(source, "\n")
+	version := -1
+	for i, line := range lines {
+		if versionDirective.MatchString(line) {
+			version = i
+			break
+		}
+	}
+
+	if version == -1 {
+		output = append(output, preprocessor.defines()...)
+		output = append(output, "#line 1 0")
+	}
+
+	for i, line := range lines {
+		if i == version {
+			output = append(output, line)
+			output = append(output, preprocessor.defines()...)
+			output = append(output, fmt.Sprintf("#line %d 0", i + 2))
+			continue
+		}
+
+		expanded, err := preprocessor.expand(result, []string{result.Files[0]}, 0, i + 1, line)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, expanded...)
+	}
+
+	result.Source = strings.Join(output, "\n")
+	return result, nil
+}
+
+// Expands a line of a file, the stack has the files being included (the last one is the one the line is from)
+func (preprocessor *Preprocessor) expand(result *Result, stack []string, index, number int, line string) ([]string, error) {
+	current := stack[len(stack) - 1]
+
+	directive := includeDirective.FindStringSubmatch(line)
+	if directive == nil {
+		if len(stack) > 1 && versionDirective.MatchString(line) {
+			return nil, fmt.Errorf("%s:%d: #version is only allowed in the main shader", current, number)
+		}
+		return []string{line}, nil
+	}
+
+	name := includePath.FindStringSubmatch(strings.TrimSpace(directive[1]))
+	if name == nil {
+		return nil, fmt.Errorf("%s:%d: malformed #include, expected #include \"file\"", current, number)
+	}
+
+	path := filepath.Join(filepath.Dir(current), name[1])
+	for i, including := range stack {
+		if including == path {
+			cycle := append(append([]string{}, stack[i:]...), path)
+			return nil, fmt.Errorf("%s:%d: include cycle: %s", current, number, strings.Join(cycle, " -> "))
+		}
+	}
+
+	source, err := preprocessor.read(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: %v", current, number, err)
+	}
+
+	included := result.fileIndex(path)
+	output := []string{fmt.Sprintf("#line 1 %d", included)}
+	including := append(append([]string{}, stack...), path)
+
+	for i, includedLine := range strings.Split(source, "\n") {
+		expanded, err := preprocessor.expand(result, including, included, i + 1, includedLine)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, expanded...)
+	}
+
+	// Back to the line after the #include
+	return append(output, fmt.Sprintf("#line %d %d", number + 1, index)), nil
+}
+
+// Reads a file with the line endings as "\n" and without the null terminator ReadFile adds for OpenGL
+func (preprocessor *Preprocessor) read(path string) (string, error) {
+	source, err := preprocessor.readFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	source = strings.TrimRight(source, "\x00")
+	return strings.Replace(source, "\r\n", "\n", -1), nil
+}
+
+// Returns the #define lines, sorted by name so the source is the same every time
+func (preprocessor *Preprocessor) defines() []string {
+	names := make([]string, 0, len(preprocessor.Defines))
+	for name := range preprocessor.Defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("#define %s %s", name, preprocessor.Defines[name])))
+	}
+
+	return lines
+}
+
+// Returns the index of a file in the list of files used, adding it if it isn't there
+func (result *Result) fileIndex(path string) int {
+	for i, file := range result.Files {
+		if file == path {
+			return i
+		}
+	}
+
+	result.Files = append(result.Files, path)
+	return len(result.Files) - 1
+}
+
+// Line of a compiler log, with the file and the line it is about (File is empty if the log line isn't about one)
+type LogLine struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// Formats the log line as file:line: message, so editors can jump to it
+func (line LogLine) String() string {
+	if line.File == "" {
+		return line.Message
+	}
+
+	return fmt.Sprintf("%s:%d: %s", line.File, line.Line, line.Message)
+}
+
+//
+// Parse Log
+// Finds the file and the line each line of a compiler log of the preprocessed source is about
+// (lines in formats it doesn't know are kept as the message, without a file)
+//
+// @param log (string) the compiler log
+//
+// @return lines ([]LogLine) the lines of the log
+//
+func (result *Result) ParseLog(log string) []LogLine {
+	var lines []LogLine
+
+	for _, line := range strings.Split(strings.TrimRight(log, "\x00\n "), "\n") {
+		match := logLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			lines = append(lines, LogLine{"", 0, line})
+			continue
+		}
+
+		file := match[2]
+		var index, number int
+		if _, err := fmt.Sscan(match[2], &index); err == nil && index < len(result.Files) {
+			file = result.Files[index]
+		}
+		fmt.Sscan(match[3], &number)
+
+		message := match[4]
+		if match[1] != "" {
+			message = strings.ToLower(match[1]) + ": " + message
+		}
+		lines = append(lines, LogLine{file, number, message})
+	}
+
+	return lines
+}
+
+//
+// Format Log
+// Rewrites the line numbers of a compiler log of the preprocessed source as file:line, so editors can jump to them
+// (lines in formats it doesn't know are kept as they are)
+//
+// @param log (string) the compiler log
+//
+// @return log (string) the formatted log
+//
+func (result *Result) FormatLog(log string) string {
+	var formatted []string
+	for _, line := range result.ParseLog(log) {
+		formatted = append(formatted, line.String())
+	}
+
+	return strings.Join(formatted, "\n")
+}
@@ -0,0 +1,197 @@
+package glsl
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Creates a preprocessor that reads the files from a map instead of the disk
+func memory(files map[string]string) *Preprocessor {
+	return New(func(path string) (string, error) {
+		source, found := files[filepath.ToSlash(path)]
+		if !found {
+			return "", fmt.Errorf("open %s: no such file or directory", path)
+		}
+		return source, nil
+	})
+}
+
+func TestProcessWithoutIncludes(t *testing.T) {
+	result, err := memory(map[string]string{
+		"shaders/basic.vert": "// Comment\n#version 330\nvoid main() {}",
+	}).Process("shaders/basic.vert")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "// Comment\n#version 330\n#line 3 0\nvoid main() {}"
+	if result.Source != expected {
+		t.Errorf("source:\n%s\nexpected:\n%s", result.Source, expected)
+	}
+	if len(result.Files) != 1 || result.Files[0] != filepath.Clean("shaders/basic.vert") {
+		t.Errorf("files %v, expected only the shader", result.Files)
+	}
+}
+
+func TestProcessDefines(t *testing.T) {
+	preprocessor := memory(map[string]string{
+		"basic.frag": "#version 330\nout vec4 colour;",
+		"noversion.frag": "out vec4 colour;",
+	})
+	preprocessor.Define("USE_PHONG", "")
+	preprocessor.Define("MAX_LIGHTS", "8")
+
+	result, err := preprocessor.Process("basic.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The defines go after #version, sorted by name, and the line numbers continue where the file left them
+	expected := "#version 330\n#define MAX_LIGHTS 8\n#define USE_PHONG\n#line 2 0\nout vec4 colour;"
+	if result.Source != expected {
+		t.Errorf("source:\n%s\nexpected:\n%s", result.Source, expected)
+	}
+
+	result, err = preprocessor.Process("noversion.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "#define MAX_LIGHTS 8\n#define USE_PHONG\n#line 1 0\nout vec4 colour;"
+	if result.Source != expected {
+		t.Errorf("source:\n%s\nexpected:\n%s", result.Source, expected)
+	}
+}
+
+func TestProcessIncludes(t *testing.T) {
+	result, err := memory(map[string]string{
+		"shaders/basic.frag": "#version 330\n#include \"lighting.glsl\"\nvoid main() {}\n#include \"common/maths.glsl\" // Again",
+		"shaders/lighting.glsl": "#include \"common/maths.glsl\"\nvec3 shade();",
+		"shaders/common/maths.glsl": "float square(float x);",
+	}).Process("shaders/basic.frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"#version 330",
+		"#line 2 0",
+		"#line 1 1",
+		"#line 1 2",
+		"float square(float x);",
+		"#line 2 1",
+		"vec3 shade();",
+		"#line 3 0",
+		"void main() {}",
+		"#line 1 2",
+		"float square(float x);",
+		"#line 5 0",
+	}, "\n")
+	if result.Source != expected {
+		t.Errorf("source:\n%s\nexpected:\n%s", result.Source, expected)
+	}
+
+	files := []string{"shaders/basic.frag", "shaders/lighting.glsl", "shaders/common/maths.glsl"}
+	if len(result.Files) != len(files) {
+		t.Fatalf("files %v, expected %v", result.Files, files)
+	}
+	for i, file := range files {
+		if result.Files[i] != filepath.Clean(file) {
+			t.Errorf("file %d is %s, expected %s", i, result.Files[i], file)
+		}
+	}
+}
+
+func TestProcessErrors(t *testing.T) {
+	preprocessor := memory(map[string]string{
+		"self.glsl": "#include \"self.glsl\"",
+		"a.glsl": "// A\n#include \"b.glsl\"",
+		"b.glsl": "#include \"a.glsl\"",
+		"missing.glsl": "\n#include \"nothing.glsl\"",
+		"malformed.glsl": "#include <lighting.glsl>",
+		"main.vert": "#version 330\n#include \"version.glsl\"",
+		"version.glsl": "#version 330",
+	})
+
+	tests := []struct {
+		path, message string
+	}{
+		{"self.glsl", "self.glsl:1: include cycle: self.glsl -> self.glsl"},
+		{"a.glsl", "b.glsl:1: include cycle: a.glsl -> b.glsl -> a.glsl"},
+		{"missing.glsl", "missing.glsl:2: open nothing.glsl"},
+		{"malformed.glsl", "malformed.glsl:1: malformed #include"},
+		{"main.vert", "version.glsl:1: #version is only allowed in the main shader"},
+		{"nothing.glsl", "open nothing.glsl"},
+	}
+
+	for _, test := range tests {
+		_, err := preprocessor.Process(test.path)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.path)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%s: error %q, expected it to contain %q", test.path, err, test.message)
+		}
+	}
+}
+
+func TestProcessWindowsLineEndings(t *testing.T) {
+	result, err := memory(map[string]string{
+		"basic.vert": "#version 330\r\n#include \"lighting.glsl\"\r\nvoid main() {}\x00",
+		"lighting.glsl": "vec3 shade();\r\n",
+	}).Process("basic.vert")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "#version 330\n#line 2 0\n#line 1 1\nvec3 shade();\n\n#line 3 0\nvoid main() {}"
+	if result.Source != expected {
+		t.Errorf("source:\n%q\nexpected:\n%q", result.Source, expected)
+	}
+}
+
+func TestFormatLog(t *testing.T) {
+	result := &Result{"", []string{"shaders/basic.frag", "shaders/lighting.glsl"}}
+
+	log := strings.Join([]string{
+		"0(12) : error C1008: undefined variable \"colour\"",
+		"1:40(5): error: `normal' undeclared",
+		"ERROR: 1:7: 'shade' : no matching overloaded function found",
+		"WARNING: 3:2: unknown file",
+		"2 compilation errors",
+	}, "\n") + "\n\x00"
+
+	expected := strings.Join([]string{
+		"shaders/basic.frag:12: error C1008: undefined variable \"colour\"",
+		"shaders/lighting.glsl:40: error: `normal' undeclared",
+		"shaders/lighting.glsl:7: error: 'shade' : no matching overloaded function found",
+		"3:2: warning: unknown file",
+		"2 compilation errors",
+	}, "\n")
+
+	if formatted := result.FormatLog(log); formatted != expected {
+		t.Errorf("log:\n%s\nexpected:\n%s", formatted, expected)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	result := &Result{"", []string{"shaders/basic.vert", "shaders/lighting.glsl"}}
+
+	lines := result.ParseLog("ERROR: 1:42: 'lights' : undeclared identifier\nERROR: 1 compilation errors.  No code generated.\n\x00")
+
+	expected := []LogLine{
+		{"shaders/lighting.glsl", 42, "error: 'lights' : undeclared identifier"},
+		{"", 0, "ERROR: 1 compilation errors.  No code generated."},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("lines %v, expected %v", lines, expected)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d is %+v, expected %+v", i, lines[i], expected[i])
+		}
+	}
+}
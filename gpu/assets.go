@@ -0,0 +1,95 @@
+package gpu
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Where the shaders, textures and data files are read from, the folder the app runs from until SetAssets is called
+var assets fs.FS = os.DirFS(".")
+
+// Looks for the files in each file system in order, the first one that has a file wins
+type overlayFS []fs.FS
+
+func (layers overlayFS) Open(name string) (fs.File, error) {
+	for _, layer := range layers {
+		file, err := layer.Open(name)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return file, err
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+//
+// New Asset FS
+// Creates the file system the assets are read from: the files in the override folder (if any) replace the
+// embedded ones, so the assets can be edited while developing without rebuilding the app
+//
+// @param embedded (fs.FS) the assets embedded in the binary
+// @param overrideDir (string) folder with the assets to use instead of the embedded ones ("" to use only the embedded ones)
+//
+// @return assets (fs.FS) the asset file system
+//
+func NewAssetFS(embedded fs.FS, overrideDir string) fs.FS {
+	if overrideDir == "" {
+		return embedded
+	}
+
+	return overlayFS{os.DirFS(overrideDir), embedded}
+}
+
+// Sets the file system the assets are read from (see NewAssetFS)
+func SetAssets(fsys fs.FS) {
+	assets = fsys
+}
+
+// Returns the file system the assets are read from
+func Assets() fs.FS {
+	return assets
+}
+
+//
+// Open Asset
+// Opens an asset. The path is relative to the root of the assets ("./shaders/basic.vert" and "shaders/basic.vert"
+// are the same file), absolute paths are opened from the disk.
+//
+// @param name (string) the path to the asset
+//
+// @return file (fs.File) the file, to be closed by the caller
+// @return error (error) the error (an *fs.PathError, fs.ErrNotExist if the asset is missing)
+//
+func OpenAsset(name string) (fs.File, error) {
+	if filepath.IsAbs(name) {
+		return os.Open(name)
+	}
+
+	return assets.Open(assetPath(name))
+}
+
+//
+// Stat Asset
+// Gets the size and the modification time of an asset (embedded assets don't have a modification time)
+//
+// @param name (string) the path to the asset
+//
+// @return info (fs.FileInfo) the file information
+// @return error (error) the error (an *fs.PathError, fs.ErrNotExist if the asset is missing)
+//
+func StatAsset(name string) (fs.FileInfo, error) {
+	if filepath.IsAbs(name) {
+		return os.Stat(name)
+	}
+
+	return fs.Stat(assets, assetPath(name))
+}
+
+// Converts a path to the form io/fs expects (slashes, no "./" or trailing slash)
+func assetPath(name string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+}
@@ -0,0 +1,63 @@
+package gpu
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Draw calls and triangles since the last TakeDrawCounts, counted by DrawArrays and DrawElements
+var drawCalls, triangles int64
+
+//
+// Draw Arrays
+// Calls gl.DrawArrays and counts the draw call and its triangles (for the frame statistics)
+//
+// @param mode (uint32) the primitive type
+// @param first (int32) the first vertex
+// @param count (int32) the number of vertices
+//
+func DrawArrays(mode uint32, first, count int32) {
+	CountDraw(mode, count)
+	gl.DrawArrays(mode, first, count)
+}
+
+//
+// Draw Elements
+// Calls gl.DrawElements and counts the draw call and its triangles (for the frame statistics)
+//
+// @param mode (uint32) the primitive type
+// @param count (int32) the number of indices
+// @param xtype (uint32) the type of the indices
+// @param indices (unsafe.Pointer) the offset into the element buffer
+//
+func DrawElements(mode uint32, count int32, xtype uint32, indices unsafe.Pointer) {
+	CountDraw(mode, count)
+	gl.DrawElements(mode, count, xtype, indices)
+}
+
+// Counts a draw call and the triangles it draws, for draws that don't go through DrawArrays or DrawElements
+func CountDraw(mode uint32, count int32) {
+	atomic.AddInt64(&drawCalls, 1)
+
+	switch mode {
+	case gl.TRIANGLES:
+		atomic.AddInt64(&triangles, int64(count / 3))
+	case gl.TRIANGLE_STRIP, gl.TRIANGLE_FAN:
+		if count > 2 {
+			atomic.AddInt64(&triangles, int64(count - 2))
+		}
+	}
+}
+
+//
+// Take Draw Counts
+// Returns the draw calls and triangles counted since the last call, and starts counting again from 0
+//
+// @return drawCalls (int) the number of draw calls
+// @return triangles (int) the number of triangles
+//
+func TakeDrawCounts() (int, int) {
+	return int(atomic.SwapInt64(&drawCalls, 0)), int(atomic.SwapInt64(&triangles, 0))
+}
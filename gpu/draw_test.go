@@ -0,0 +1,31 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+func TestCountDraw(t *testing.T) {
+	TakeDrawCounts()
+
+	CountDraw(gl.TRIANGLES, 36)
+	CountDraw(gl.TRIANGLE_STRIP, 6)
+	CountDraw(gl.TRIANGLE_FAN, 5)
+	CountDraw(gl.TRIANGLE_STRIP, 2) // Not enough vertices for a triangle
+	CountDraw(gl.POINTS, 100)
+	CountDraw(gl.LINES, 10)
+
+	drawCalls, triangles := TakeDrawCounts()
+	if drawCalls != 6 {
+		t.Errorf("%d draw calls, expected 6", drawCalls)
+	}
+	if triangles != 12 + 4 + 3 {
+		t.Errorf("%d triangles, expected %d", triangles, 12 + 4 + 3)
+	}
+
+	// Taking the counts starts again from 0
+	if drawCalls, triangles = TakeDrawCounts(); drawCalls != 0 || triangles != 0 {
+		t.Errorf("counts after taking them are %d and %d, expected 0", drawCalls, triangles)
+	}
+}
@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"fmt"
+	"strings"
+
+	"../glsl"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// A shader that didn't compile, with the compiler log split into the lines of the files it is about
+type ShaderCompileError struct {
+	Stage string         // "vertex" or "fragment"
+	Path  string         // The shader file (the errors can be in the files it includes)
+	Lines []glsl.LogLine // The compiler log, one line per error or warning
+}
+
+func (err *ShaderCompileError) Error() string {
+	lines := make([]string, len(err.Lines))
+	for i, line := range err.Lines {
+		lines[i] = line.String()
+	}
+
+	return fmt.Sprintf("failed to compile the %s shader %s:\n%s", err.Stage, err.Path, strings.Join(lines, "\n"))
+}
+
+// A shader program that didn't link (the shaders compiled but don't fit together)
+type LinkError struct {
+	VertexPath, FragmentPath string
+	Log                      string // The linker log
+}
+
+func (err *LinkError) Error() string {
+	return fmt.Sprintf("failed to link %s and %s:\n%s", err.VertexPath, err.FragmentPath, err.Log)
+}
+
+// Returns the name of a shader stage, for the errors
+func shaderStage(shaderType uint32) string {
+	switch shaderType {
+	case gl.VERTEX_SHADER:
+		return "vertex"
+	case gl.FRAGMENT_SHADER:
+		return "fragment"
+	case gl.GEOMETRY_SHADER:
+		return "geometry"
+	}
+
+	return fmt.Sprintf("0x%x", shaderType)
+}
@@ -0,0 +1,99 @@
+package gpu
+
+import (
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Where a vertex attribute is read from: a buffer, the number and type of its components and where they are in the buffer
+type VertexAttribute struct {
+	Location   uint32 // Attribute index in the shaders (layout(location = N))
+	Buffer     uint32 // Buffer object the attribute is read from
+	Components int32  // Number of components (1 to 4)
+	Type       uint32 // Type of the components (gl.FLOAT, gl.UNSIGNED_BYTE...)
+	Normalized bool   // Integer components are mapped to 0..1 (or -1..1 if signed)
+	Stride     int32  // Bytes from one vertex to the next (0 if the buffer only has this attribute)
+	Offset     int    // Bytes from the start of the buffer to the first component
+}
+
+// Describes the vertices of a mesh, to configure a vertex array object once instead of on every draw
+type VertexLayout struct {
+	Attributes []VertexAttribute
+	Elements   uint32 // Element (index) buffer, 0 if the mesh isn't indexed
+}
+
+// Format of an attribute in an interleaved buffer of floats (see Interleaved)
+type AttributeFormat struct {
+	Location   uint32
+	Components int32
+}
+
+//
+// Float Attribute
+// Describes an attribute of floats that has a buffer of its own
+//
+// @param location (uint32) the attribute index in the shaders
+// @param buffer (uint32) the buffer object
+// @param components (int32) the number of floats per vertex
+//
+// @return attribute (VertexAttribute) the attribute
+//
+func FloatAttribute(location, buffer uint32, components int32) VertexAttribute {
+	return VertexAttribute{location, buffer, components, gl.FLOAT, false, 0, 0}
+}
+
+//
+// Interleaved
+// Describes the attributes of a buffer of floats with every attribute of a vertex next to each other,
+// in the order they are given (position, colour, normal... position, colour, normal...)
+//
+// @param buffer (uint32) the buffer object
+// @param formats (...AttributeFormat) the attributes of each vertex, in order
+//
+// @return attributes ([]VertexAttribute) the attributes, with their stride and offsets
+//
+func Interleaved(buffer uint32, formats ...AttributeFormat) []VertexAttribute {
+	var stride int32
+	for _, format := range formats {
+		stride += format.Components * 4
+	}
+
+	attributes := make([]VertexAttribute, len(formats))
+	offset := 0
+	for i, format := range formats {
+		attributes[i] = VertexAttribute{format.Location, buffer, format.Components, gl.FLOAT, false, stride, offset}
+		offset += int(format.Components) * 4
+	}
+
+	return attributes
+}
+
+//
+// New Vertex Array
+// Creates a vertex array object configured with a layout (the buffers have to be created already).
+// Binding it before drawing sets every attribute and the element buffer at once.
+//
+// @param label (string) what the vertex array is for, shown in the leak report
+// @param layout (VertexLayout) the attributes and the element buffer
+//
+// @return vertexArray (uint32) the vertex array object
+//
+func NewVertexArray(label string, layout VertexLayout) uint32 {
+	vertexArray := GenVertexArray(label)
+	gl.BindVertexArray(vertexArray)
+
+	for _, attribute := range layout.Attributes {
+		gl.BindBuffer(gl.ARRAY_BUFFER, attribute.Buffer)
+		gl.EnableVertexAttribArray(attribute.Location)
+		gl.VertexAttribPointer(attribute.Location, attribute.Components, attribute.Type, attribute.Normalized, attribute.Stride, gl.PtrOffset(attribute.Offset))
+	}
+
+	// The element buffer binding is part of the vertex array
+	if layout.Elements != 0 {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, layout.Elements)
+	}
+
+	gl.BindVertexArray(0)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	return vertexArray
+}
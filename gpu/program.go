@@ -0,0 +1,208 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Linked shader program, with the locations of its active uniforms and attributes
+type Shader struct {
+	Program    uint32
+	Files      []string // The files the program was built from (the shaders and the ones they include)
+
+	uniforms   map[string]int32 // Locations of the active uniforms by name
+	attributes map[string]int32 // Locations of the active attributes by name
+	warned     map[string]bool  // Names already reported as unknown
+}
+
+//
+// New Shader
+// Wraps a linked program and reads the names and locations of its active uniforms and attributes.
+// Arrays can be found by their name, with or without an index ("lights", "lights[0]", "lights[1]"...).
+//
+// @param program (uint32) the linked program
+//
+// @return shader (*Shader) a pointer to the shader
+//
+func NewShader(program uint32) *Shader {
+	shader := &Shader{
+		program, // program
+		nil, // files
+		make(map[string]int32), // uniforms
+		make(map[string]int32), // attributes
+		make(map[string]bool), // warned
+	}
+	TrackResource(RESOURCE_PROGRAM, program, "shader program")
+
+	var count, maxLength int32
+
+	// Active uniforms (the ones the compiler didn't optimise away)
+	gl.GetProgramiv(program, gl.ACTIVE_UNIFORMS, &count)
+	gl.GetProgramiv(program, gl.ACTIVE_UNIFORM_MAX_LENGTH, &maxLength)
+
+	for i := uint32(0); i < uint32(count); i++ {
+		var length, size int32
+		var xtype uint32
+		name := make([]uint8, maxLength + 1)
+		gl.GetActiveUniform(program, i, maxLength + 1, &length, &size, &xtype, &name[0])
+
+		shader.addUniform(string(name[:length]), size)
+	}
+
+	// Active attributes
+	gl.GetProgramiv(program, gl.ACTIVE_ATTRIBUTES, &count)
+	gl.GetProgramiv(program, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH, &maxLength)
+
+	for i := uint32(0); i < uint32(count); i++ {
+		var length, size int32
+		var xtype uint32
+		name := make([]uint8, maxLength + 1)
+		gl.GetActiveAttrib(program, i, maxLength + 1, &length, &size, &xtype, &name[0])
+
+		shader.attributes[string(name[:length])] = gl.GetAttribLocation(program, gl.Str(string(name[:length]) + "\x00"))
+	}
+
+	return shader
+}
+
+// Adds an active uniform, arrays are reported as "name[0]" with their size, each element gets its own location
+func (shader *Shader) addUniform(name string, size int32) {
+	if !strings.HasSuffix(name, "[0]") {
+		shader.uniforms[name] = gl.GetUniformLocation(shader.Program, gl.Str(name + "\x00"))
+		return
+	}
+
+	base := strings.TrimSuffix(name, "[0]")
+	for i := int32(0); i < size; i++ {
+		element := fmt.Sprintf("%s[%d]", base, i)
+		shader.uniforms[element] = gl.GetUniformLocation(shader.Program, gl.Str(element + "\x00"))
+	}
+	shader.uniforms[base] = shader.uniforms[name]
+}
+
+//
+// Uniform
+// Returns the location of a uniform. If the program doesn't have it (or it was optimised away)
+// a warning is printed the first time and -1 is returned, which OpenGL ignores.
+//
+// @param name (string) the name of the uniform
+//
+// @return location (int32) the location of the uniform, or -1
+//
+func (shader *Shader) Uniform(name string) int32 {
+	if location, found := shader.uniforms[name]; found {
+		return location
+	}
+
+	if !shader.warned[name] {
+		shader.warned[name] = true
+		fmt.Printf("Warning: the shader program has no active uniform %q\n", name)
+	}
+
+	return -1
+}
+
+// Checks if the program has an active uniform, without warning if it doesn't
+func (shader *Shader) HasUniform(name string) bool {
+	_, found := shader.uniforms[name]
+	return found
+}
+
+// Returns the location of an attribute, or -1 if the program doesn't have it
+func (shader *Shader) Attribute(name string) int32 {
+	if location, found := shader.attributes[name]; found {
+		return location
+	}
+
+	return -1
+}
+
+// Returns the names of the active uniforms in alphabetical order
+func (shader *Shader) Uniforms() []string {
+	names := make([]string, 0, len(shader.uniforms))
+	for name := range shader.uniforms {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Makes the program the one used to draw
+func (shader *Shader) Use() {
+	gl.UseProgram(shader.Program)
+}
+
+// Frees the program (does nothing if it was already released)
+func (shader *Shader) Release() {
+	if shader.Program == 0 {
+		return
+	}
+
+	gl.DeleteProgram(shader.Program)
+	UntrackResource(RESOURCE_PROGRAM, shader.Program)
+	shader.Program = 0
+}
+
+// The setters below write to the program in use (call Use first)
+
+func (shader *Shader) SetMat4(name string, value mgl32.Mat4) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.UniformMatrix4fv(location, 1, false, &value[0])
+	}
+}
+
+func (shader *Shader) SetMat3(name string, value mgl32.Mat3) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.UniformMatrix3fv(location, 1, false, &value[0])
+	}
+}
+
+func (shader *Shader) SetVec4(name string, value mgl32.Vec4) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.Uniform4fv(location, 1, &value[0])
+	}
+}
+
+func (shader *Shader) SetVec3(name string, value mgl32.Vec3) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.Uniform3fv(location, 1, &value[0])
+	}
+}
+
+func (shader *Shader) SetVec2(name string, value mgl32.Vec2) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.Uniform2fv(location, 1, &value[0])
+	}
+}
+
+func (shader *Shader) SetFloat(name string, value float32) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.Uniform1f(location, value)
+	}
+}
+
+func (shader *Shader) SetInt(name string, value int32) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.Uniform1i(location, value)
+	}
+}
+
+func (shader *Shader) SetUint(name string, value uint32) {
+	if location := shader.Uniform(name); location != -1 {
+		gl.Uniform1ui(location, value)
+	}
+}
+
+func (shader *Shader) SetBool(name string, value bool) {
+	var integer int32
+	if value {
+		integer = 1
+	}
+
+	shader.SetInt(name, integer)
+}
@@ -0,0 +1,150 @@
+package gpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+type ResourceType int32
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	RESOURCE_BUFFER ResourceType = 0 + iota
+	RESOURCE_VERTEX_ARRAY
+	RESOURCE_PROGRAM
+	RESOURCE_TEXTURE
+	RESOURCE_FRAMEBUFFER
+	RESOURCE_RENDERBUFFER
+	RESOURCE_QUERY
+)
+
+var resourceTypeNames = [...]string{"_", "buffer", "vertex array", "program", "texture", "framebuffer", "renderbuffer", "query"}
+
+func (resourceType ResourceType) String() string {
+	if resourceType <= 0 || int(resourceType) >= len(resourceTypeNames) {
+		return fmt.Sprintf("ResourceType(%d)", int32(resourceType))
+	}
+
+	return resourceTypeNames[resourceType]
+}
+
+// A GPU object that is alive (created and not deleted yet)
+type Resource struct {
+	Type  ResourceType
+	Id    uint32
+	Label string // What the object is for ("sphere positions", "shader program"...), shown in the leak report
+}
+
+type resourceKey struct {
+	Type ResourceType
+	Id   uint32
+}
+
+// Every GPU object created through these helpers that hasn't been released
+var liveResources = make(map[resourceKey]Resource)
+
+// Adds a GPU object to the registry of live objects (ids of 0 are ignored, they are not objects)
+func TrackResource(resourceType ResourceType, id uint32, label string) {
+	if id == 0 {
+		return
+	}
+
+	liveResources[resourceKey{resourceType, id}] = Resource{resourceType, id, label}
+}
+
+// Removes a deleted GPU object from the registry of live objects
+func UntrackResource(resourceType ResourceType, id uint32) {
+	delete(liveResources, resourceKey{resourceType, id})
+}
+
+// Returns the GPU objects that haven't been released, sorted by type and id
+func LiveResources() []Resource {
+	resources := make([]Resource, 0, len(liveResources))
+	for _, resource := range liveResources {
+		resources = append(resources, resource)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Type != resources[j].Type {
+			return resources[i].Type < resources[j].Type
+		}
+		return resources[i].Id < resources[j].Id
+	})
+
+	return resources
+}
+
+//
+// Resource Report
+// Describes the GPU objects that haven't been released (Terminate prints it if there are any)
+//
+// @return report (string) the report, empty if every object was released
+//
+func ResourceReport() string {
+	resources := LiveResources()
+	if len(resources) == 0 {
+		return ""
+	}
+
+	lines := []string{fmt.Sprintf("%d GPU resources were not released:", len(resources))}
+	for _, resource := range resources {
+		lines = append(lines, fmt.Sprintf("  %s %d (%s)", resource.Type, resource.Id, resource.Label))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+//
+// Gen Buffer
+// Creates a buffer object and adds it to the registry
+//
+// @param label (string) what the buffer is for, shown in the leak report
+//
+// @return buffer (uint32) the buffer object
+//
+func GenBuffer(label string) uint32 {
+	var buffer uint32
+	gl.GenBuffers(1, &buffer)
+	TrackResource(RESOURCE_BUFFER, buffer, label)
+	return buffer
+}
+
+// Deletes a buffer object and sets it to 0 (does nothing if it is already 0)
+func DeleteBuffer(buffer *uint32) {
+	if *buffer == 0 {
+		return
+	}
+
+	gl.DeleteBuffers(1, buffer)
+	UntrackResource(RESOURCE_BUFFER, *buffer)
+	*buffer = 0
+}
+
+//
+// Gen Vertex Array
+// Creates a vertex array object and adds it to the registry
+//
+// @param label (string) what the vertex array is for, shown in the leak report
+//
+// @return vertexArray (uint32) the vertex array object
+//
+func GenVertexArray(label string) uint32 {
+	var vertexArray uint32
+	gl.GenVertexArrays(1, &vertexArray)
+	TrackResource(RESOURCE_VERTEX_ARRAY, vertexArray, label)
+	return vertexArray
+}
+
+// Deletes a vertex array object and sets it to 0 (does nothing if it is already 0)
+func DeleteVertexArray(vertexArray *uint32) {
+	if *vertexArray == 0 {
+		return
+	}
+
+	gl.DeleteVertexArrays(1, vertexArray)
+	UntrackResource(RESOURCE_VERTEX_ARRAY, *vertexArray)
+	*vertexArray = 0
+}
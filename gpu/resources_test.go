@@ -0,0 +1,94 @@
+package gpu
+
+import (
+	"testing"
+)
+
+// Runs a test with an empty registry, putting back the one other tests left
+func withEmptyRegistry(t *testing.T, test func(t *testing.T)) {
+	previous := liveResources
+	liveResources = make(map[resourceKey]Resource)
+	defer func() { liveResources = previous }()
+
+	test(t)
+}
+
+func TestTrackResource(t *testing.T) {
+	withEmptyRegistry(t, func(t *testing.T) {
+		TrackResource(RESOURCE_TEXTURE, 3, "texture")
+		TrackResource(RESOURCE_BUFFER, 7, "sphere positions")
+		TrackResource(RESOURCE_BUFFER, 2, "cube positions")
+		TrackResource(RESOURCE_PROGRAM, 1, "shader program")
+
+		// Ids of 0 are not objects
+		TrackResource(RESOURCE_BUFFER, 0, "failed buffer")
+
+		// Sorted by type and then by id
+		expected := []Resource{
+			{RESOURCE_BUFFER, 2, "cube positions"},
+			{RESOURCE_BUFFER, 7, "sphere positions"},
+			{RESOURCE_PROGRAM, 1, "shader program"},
+			{RESOURCE_TEXTURE, 3, "texture"},
+		}
+		live := LiveResources()
+		if len(live) != len(expected) {
+			t.Fatalf("live resources are %v, expected %v", live, expected)
+		}
+		for i := range expected {
+			if live[i] != expected[i] {
+				t.Errorf("live resource %d is %v, expected %v", i, live[i], expected[i])
+			}
+		}
+
+		// The same id of another type is another object
+		UntrackResource(RESOURCE_TEXTURE, 7)
+		UntrackResource(RESOURCE_BUFFER, 7)
+		UntrackResource(RESOURCE_BUFFER, 0)
+		if live = LiveResources(); len(live) != 3 || live[1].Type != RESOURCE_PROGRAM {
+			t.Errorf("live resources after untracking buffer 7 are %v", live)
+		}
+
+		report := ResourceReport()
+		expectedReport := "3 GPU resources were not released:\n" +
+			"  buffer 2 (cube positions)\n" +
+			"  program 1 (shader program)\n" +
+			"  texture 3 (texture)\n"
+		if report != expectedReport {
+			t.Errorf("report is %q, expected %q", report, expectedReport)
+		}
+	})
+}
+
+func TestResourceReportWhenReleased(t *testing.T) {
+	withEmptyRegistry(t, func(t *testing.T) {
+		if report := ResourceReport(); report != "" {
+			t.Errorf("report of an empty registry is %q, expected it empty", report)
+		}
+
+		TrackResource(RESOURCE_FRAMEBUFFER, 4, "offscreen framebuffer")
+		TrackResource(RESOURCE_RENDERBUFFER, 4, "offscreen colour buffer")
+		UntrackResource(RESOURCE_FRAMEBUFFER, 4)
+		UntrackResource(RESOURCE_RENDERBUFFER, 4)
+
+		if live := LiveResources(); len(live) != 0 {
+			t.Errorf("live resources are %v, expected none", live)
+		}
+		if report := ResourceReport(); report != "" {
+			t.Errorf("report after releasing everything is %q, expected it empty", report)
+		}
+
+		// Deleting an object that was never created doesn't touch GL
+		var buffer, vertexArray uint32
+		DeleteBuffer(&buffer)
+		DeleteVertexArray(&vertexArray)
+	})
+}
+
+func TestResourceTypeString(t *testing.T) {
+	if name := RESOURCE_VERTEX_ARRAY.String(); name != "vertex array" {
+		t.Errorf("name is %q, expected \"vertex array\"", name)
+	}
+	if name := ResourceType(42).String(); name != "ResourceType(42)" {
+		t.Errorf("name of an unknown type is %q", name)
+	}
+}
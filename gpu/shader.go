@@ -0,0 +1,144 @@
+package gpu
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shader program built from a vertex and a fragment shader file, rebuilt when the files change
+type ShaderProgram struct {
+	*Shader                         // The last program that compiled and linked
+
+	VertexPath, FragmentPath string
+	Defines                  map[string]string // Names and values defined in both shaders
+
+	PollInterval             time.Duration // How often the files are checked for changes
+
+	modTimes                 map[string]time.Time
+	lastPoll                 time.Time
+	onReload                 func(program *ShaderProgram)
+}
+
+//
+// New Shader Program
+// Loads a shader program and remembers the modification time of its files (and the files they include),
+// so Poll can rebuild it when they change
+//
+// @param vertexPath (string) path to the vertex shader file
+// @param fragmentPath (string) path to the fragment shader file
+// @param defines (map[string]string) names and values to #define in both shaders (can be nil)
+//
+// @return program (*ShaderProgram) a pointer to the shader program
+// @return error (error) the error (if the shaders don't compile or link)
+//
+func NewShaderProgram(vertexPath, fragmentPath string, defines map[string]string) (*ShaderProgram, error) {
+	program := &ShaderProgram{
+		nil, // shader
+		vertexPath, fragmentPath, // vertex path, fragment path
+		defines, // defines
+		500 * time.Millisecond, // poll interval
+		make(map[string]time.Time), // modification times
+		time.Now(), // last poll
+		nil, // reload callback
+	}
+
+	if err := program.Reload(); err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+//
+// Reload
+// Rebuilds the program from its files. If the new program fails the last good one is kept.
+//
+// @return error (error) the error (if the shaders don't compile or link)
+//
+func (program *ShaderProgram) Reload() error {
+	// The modification times are read first, so a failed build isn't retried until the files change again
+	program.modTimes[program.VertexPath] = modTime(program.VertexPath)
+	program.modTimes[program.FragmentPath] = modTime(program.FragmentPath)
+	for path := range program.modTimes {
+		program.modTimes[path] = modTime(path)
+	}
+
+	built, err := LoadShaderDefines(program.VertexPath, program.FragmentPath, program.Defines)
+	if err != nil {
+		return err
+	}
+
+	// Watches the files included by the new program too (and stops watching the ones no longer included)
+	modTimes := make(map[string]time.Time, len(built.Files))
+	for _, path := range append(built.Files, program.VertexPath, program.FragmentPath) {
+		modTimes[path] = modTime(path)
+	}
+	program.modTimes = modTimes
+
+	if program.Shader != nil {
+		program.Shader.Release()
+	}
+	program.Shader = built
+
+	if program.onReload != nil {
+		program.onReload(program)
+	}
+
+	return nil
+}
+
+//
+// Changed
+// Checks if any of the files was modified since the program was last built
+//
+// @return changed (bool) true if a file changed
+//
+func (program *ShaderProgram) Changed() bool {
+	for path, built := range program.modTimes {
+		if !modTime(path).Equal(built) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//
+// Poll
+// Rebuilds the program if its files changed (checks at most once per PollInterval, call it every frame).
+// Compile and link errors are printed and the last good program is kept.
+//
+// @return reloaded (bool) true if the program was rebuilt
+//
+func (program *ShaderProgram) Poll() bool {
+	if time.Since(program.lastPoll) < program.PollInterval {
+		return false
+	}
+	program.lastPoll = time.Now()
+
+	if !program.Changed() {
+		return false
+	}
+
+	if err := program.Reload(); err != nil {
+		fmt.Printf("Shader reload failed, keeping the last good program:\n%v\n", err)
+		return false
+	}
+
+	fmt.Printf("Reloaded shaders %s and %s\n", program.VertexPath, program.FragmentPath)
+	return true
+}
+
+// Sets a function called every time the program is rebuilt (to get the uniform locations of the new program)
+func (program *ShaderProgram) SetReloadCallback(callback func(program *ShaderProgram)) {
+	program.onReload = callback
+}
+
+// Returns the modification time of an asset (zero for the embedded ones, they can't change)
+func modTime(path string) time.Time {
+	if info, err := StatAsset(path); err == nil {
+		return info.ModTime()
+	}
+
+	return time.Time{}
+}
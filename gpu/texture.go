@@ -0,0 +1,154 @@
+package gpu
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"../imaging"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// 2D texture uploaded to the GPU
+type Texture struct {
+	Width, Height int
+
+	texture uint32
+}
+
+// How a texture is sampled
+type TextureOptions struct {
+	WrapS, WrapT         int32 // gl.REPEAT, gl.MIRRORED_REPEAT, gl.CLAMP_TO_EDGE...
+	MinFilter, MagFilter int32 // gl.LINEAR, gl.NEAREST, gl.LINEAR_MIPMAP_LINEAR...
+	Mipmaps              bool  // Generate the mipmaps (needed by the *_MIPMAP_* min filters)
+}
+
+//
+// Default Texture Options
+// Repeats the texture and filters it trilinearly with mipmaps
+//
+// @return options (TextureOptions) the options
+//
+func DefaultTextureOptions() TextureOptions {
+	return TextureOptions{
+		gl.REPEAT, gl.REPEAT, // wrap s, wrap t
+		gl.LINEAR_MIPMAP_LINEAR, gl.LINEAR, // min filter, mag filter
+		true, // mipmaps
+	}
+}
+
+//
+// Load Texture
+// Reads a PNG or JPEG asset (see OpenAsset) and uploads it as a texture.
+//
+// @param path (string) the path to the image file
+// @param options (TextureOptions) how the texture is sampled
+//
+// @return texture (*Texture) a pointer to the texture
+// @return error (error) the error (if any)
+//
+func LoadTexture(path string, options TextureOptions) (*Texture, error) {
+	file, err := OpenAsset(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := imaging.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return NewTexture(img, options)
+}
+
+//
+// New Texture
+// Uploads an image as an RGBA texture
+//
+// @param img (image.Image) the image
+// @param options (TextureOptions) how the texture is sampled
+//
+// @return texture (*Texture) a pointer to the texture
+// @return error (error) the error (if the image is empty)
+//
+func NewTexture(img image.Image, options TextureOptions) (*Texture, error) {
+	// Copies the image (it is flipped in place), OpenGL expects the bottom row first so v = 0 is the bottom of the image
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	imaging.FlipVertically(rgba)
+
+	width, height := rgba.Rect.Dx(), rgba.Rect.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("can't create a texture from an empty image")
+	}
+
+	texture := &Texture{width, height, 0}
+
+	gl.GenTextures(1, &texture.texture)
+	TrackResource(RESOURCE_TEXTURE, texture.texture, "texture")
+	gl.BindTexture(gl.TEXTURE_2D, texture.texture)
+
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(rgba.Pix))
+
+	texture.SetOptions(options)
+
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+	return texture, nil
+}
+
+//
+// Set Options
+// Changes how the texture is sampled (the texture is left bound to the current texture unit)
+//
+// @param options (TextureOptions) the options
+//
+func (texture *Texture) SetOptions(options TextureOptions) {
+	gl.BindTexture(gl.TEXTURE_2D, texture.texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, options.WrapS)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, options.WrapT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, options.MinFilter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, options.MagFilter)
+
+	if options.Mipmaps {
+		gl.GenerateMipmap(gl.TEXTURE_2D)
+	}
+}
+
+//
+// Bind
+// Binds the texture to a texture unit, the unit is the value of the sampler uniform
+//
+// @param unit (uint32) the texture unit (0 for gl.TEXTURE0)
+//
+func (texture *Texture) Bind(unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_2D, texture.texture)
+}
+
+//
+// Unbind
+// Unbinds any texture from a texture unit
+//
+// @param unit (uint32) the texture unit (0 for gl.TEXTURE0)
+//
+func (texture *Texture) Unbind(unit uint32) {
+	gl.ActiveTexture(gl.TEXTURE0 + unit)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+//
+// Release
+// Frees the texture (does nothing if it was already released)
+//
+func (texture *Texture) Release() {
+	if texture.texture == 0 {
+		return
+	}
+
+	gl.DeleteTextures(1, &texture.texture)
+	UntrackResource(RESOURCE_TEXTURE, texture.texture)
+	texture.texture = 0
+}
@@ -0,0 +1,195 @@
+package gpu
+import (
+	"strings"
+	"io/ioutil"
+	"../glsl"
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+//
+// Read File
+// Reads an asset (see OpenAsset) and returns a String with it's contents.
+// 						(string has a null pointer at the end (for OpenGL))
+//
+// @param path (string) the path to the file
+//
+// @return fileContents (string) the contents of the file as string
+// @return error (error) the error (if any)
+//
+func ReadFile (path string) (string, error) {
+	file, err := OpenAsset(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	fileContents, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(fileContents) + "\x00", nil
+}
+
+//
+// Preprocess Shader
+// Reads a shader and expands its #include directives (relative to the shader), adding the defines after #version
+//
+// @param source (string) the path to the shader file
+// @param defines (map[string]string) names and values to #define (can be nil)
+//
+// @return result (*glsl.Result) the preprocessed source and the files it was built from
+// @return error (error) the error (if any)
+//
+func PreprocessShader (source string, defines map[string]string) (*glsl.Result, error) {
+	preprocessor := glsl.New(ReadFile)
+	for name, value := range defines {
+		preprocessor.Define(name, value)
+	}
+
+	return preprocessor.Process(source)
+}
+
+//
+// Build Shader
+// Creates and compiles a shader
+//
+// @param source (string) the path to the shader file
+// @param shaderType (uint32) the shader type
+// @param defines (map[string]string) names and values to #define (can be nil)
+//
+// @return shader (uint32) the pointer to the shader
+// @return files ([]string) the files the shader was built from (the shader and the ones it includes)
+// @return error (error) the error (a *ShaderCompileError if the shader doesn't compile)
+//
+func BuildShader (source string, shaderType uint32, defines map[string]string) (uint32, []string, error) {
+	// Reads the File and the ones it includes
+	preprocessed, err := PreprocessShader(source, defines)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Creates the Shader Object
+	shader := gl.CreateShader(shaderType)
+
+	// Converts the preprocessed source into a C String (allocated in C memory, cgo doesn't allow passing a Go pointer to a Go pointer)
+	csources, free := gl.Strs(preprocessed.Source + "\x00")
+
+	// Loads the Shader's Source
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+
+	// Compiles the Shader
+	gl.CompileShader(shader)
+
+	// Gets any errors that happened when building the Shader
+	var status int32
+	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
+
+	// If there was an error, parse the C Error into a Go Error and return it
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetShaderInfoLog(shader, logLength, nil, gl.Str(log))
+		gl.DeleteShader(shader)
+
+		return 0, preprocessed.Files, &ShaderCompileError{shaderStage(shaderType), source, preprocessed.ParseLog(log)}
+	}
+
+	// Returns the shader if everything is OK
+	return shader, preprocessed.Files, nil
+}
+
+//
+// Load Shader
+// Load vertex and fragment shader and return the compiled program, with the locations of its uniforms.
+//
+// @param vertexShaderSource (string) path to the vertex shader file
+// @param fragmentShaderSource (string) path to the fragment shader file
+//
+// @return shader (*Shader) a pointer to the shader program
+// @return error (error) the error (if any)
+//
+func LoadShader (vertexShaderSource, fragmentShaderSource string) (*Shader, error) {
+	return LoadShaderDefines(vertexShaderSource, fragmentShaderSource, nil)
+}
+
+//
+// Load Shader Defines
+// Same as LoadShader, with names and values defined in both shaders (like #define MAX_LIGHTS 8)
+//
+// @param vertexShaderSource (string) path to the vertex shader file
+// @param fragmentShaderSource (string) path to the fragment shader file
+// @param defines (map[string]string) names and values to #define (can be nil)
+//
+// @return shader (*Shader) a pointer to the shader program
+// @return error (error) the error (a *ShaderCompileError or a *LinkError if the shaders don't compile or link)
+//
+func LoadShaderDefines (vertexShaderSource, fragmentShaderSource string, defines map[string]string) (*Shader, error) {
+	// Loads the Vertex shader file
+	vertexShader, vertexFiles, err := BuildShader(vertexShaderSource, gl.VERTEX_SHADER, defines)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loads the fragment shader file
+	fragmentShader, fragmentFiles, err := BuildShader(fragmentShaderSource, gl.FRAGMENT_SHADER, defines)
+	if err != nil {
+		gl.DeleteShader(vertexShader)
+		return nil, err
+	}
+	// Creates the Program
+	program := gl.CreateProgram()
+
+	// Attaches the Shaders to the program
+	gl.AttachShader(program, vertexShader)
+	gl.AttachShader(program, fragmentShader)
+
+	// Links the program
+	gl.LinkProgram(program)
+
+	// The shaders are not needed once the program is linked (or failed to link)
+	gl.DeleteShader(vertexShader)
+	gl.DeleteShader(fragmentShader)
+
+	// Gets any error that happened when linking the program
+	var status int32
+	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
+
+	// If there was any error, parse the C error and return it as a Go error
+	if status == gl.FALSE {
+		var logLength int32
+		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
+
+		log := strings.Repeat("\x00", int(logLength+1))
+		gl.GetProgramInfoLog(program, logLength, nil, gl.Str(log))
+		gl.DeleteProgram(program)
+
+		return nil, &LinkError{vertexShaderSource, fragmentShaderSource, strings.TrimRight(log, "\x00\n ")}
+	}
+
+	// returns the program, with its active uniforms and attributes and the files it was built from
+	shader := NewShader(program)
+	shader.Files = mergeFiles(vertexFiles, fragmentFiles)
+
+	return shader, nil
+}
+
+// Joins lists of files, without repeating the files that are in more than one
+func mergeFiles(lists ...[]string) []string {
+	var merged []string
+	seen := make(map[string]bool)
+
+	for _, files := range lists {
+		for _, file := range files {
+			if !seen[file] {
+				seen[file] = true
+				merged = append(merged, file)
+			}
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,220 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // Registers the JPEG decoder used by Decode
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Result of comparing two images pixel by pixel
+type Comparison struct {
+	Mismatched, Total int         // Number of pixels over the tolerance and number of pixels compared
+	MaxDifference     uint8       // Largest difference found in any channel of any pixel
+	Diff              *image.RGBA // Expected image in grey with the mismatched pixels in red
+}
+
+//
+// Load PNG
+// Reads a PNG file.
+//
+// @param path (string) the path to the PNG file
+//
+// @return image (image.Image) the image
+// @return error (error) the error (if any)
+//
+func LoadPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return img, nil
+}
+
+//
+// Load Image
+// Reads a PNG or JPEG file (the format is detected from its contents).
+//
+// @param path (string) the path to the image file
+//
+// @return image (image.Image) the image
+// @return error (error) the error (if any)
+//
+func LoadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return img, nil
+}
+
+//
+// Decode
+// Decodes a PNG or JPEG image (the format is detected from its contents).
+//
+// @param reader (io.Reader) the encoded image
+//
+// @return image (image.Image) the image
+// @return error (error) the error (if the format isn't supported or the image is corrupt)
+//
+func Decode(reader io.Reader) (image.Image, error) {
+	img, format, err := image.Decode(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != "png" && format != "jpeg" {
+		return nil, fmt.Errorf("unsupported image format %q (PNG or JPEG)", format)
+	}
+
+	return img, nil
+}
+
+//
+// Save PNG
+// Writes an image to a PNG file, creating its folder if needed.
+//
+// @param path (string) the path to the PNG file
+// @param img (image.Image) the image
+//
+// @return error (error) the error (if any)
+//
+func SavePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+//
+// To RGBA
+// Converts any image into an RGBA image (images that are already RGBA are returned as they are)
+//
+// @param img (image.Image) the image
+//
+// @return rgba (*image.RGBA) the RGBA image, with its bounds starting at 0, 0
+//
+func ToRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == image.ZP {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	return rgba
+}
+
+//
+// Flip Vertically
+// Swaps the rows of an image, so the bottom row becomes the top row
+// (OpenGL stores images bottom row first, Go images are top row first).
+//
+// @param img (*image.RGBA) the image, flipped in place
+//
+func FlipVertically(img *image.RGBA) {
+	height := img.Rect.Dy()
+	rowLength := img.Rect.Dx() * 4
+	row := make([]byte, rowLength)
+
+	for y := 0; y < height / 2; y++ {
+		top := img.Pix[y * img.Stride:y * img.Stride + rowLength]
+		bottom := img.Pix[(height - 1 - y) * img.Stride:(height - 1 - y) * img.Stride + rowLength]
+
+		copy(row, top)
+		copy(top, bottom)
+		copy(bottom, row)
+	}
+}
+
+//
+// Compare
+// Compares two images of the same size pixel by pixel.
+// A pixel is mismatched when any of its channels differs by more than the tolerance.
+//
+// @param got (image.Image) the image that was produced
+// @param want (image.Image) the expected image
+// @param tolerance (uint8) the largest difference allowed in each channel
+//
+// @return comparison (*Comparison) the result of the comparison
+// @return error (error) the error (if the images have different sizes)
+//
+func Compare(got, want image.Image, tolerance uint8) (*Comparison, error) {
+	gotRGBA, wantRGBA := ToRGBA(got), ToRGBA(want)
+	if gotRGBA.Rect.Size() != wantRGBA.Rect.Size() {
+		return nil, fmt.Errorf("image size is %v, expected %v", gotRGBA.Rect.Size(), wantRGBA.Rect.Size())
+	}
+
+	width, height := wantRGBA.Rect.Dx(), wantRGBA.Rect.Dy()
+	comparison := &Comparison{0, width * height, 0, image.NewRGBA(wantRGBA.Rect)}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			gotPixel := gotRGBA.RGBAAt(x, y)
+			wantPixel := wantRGBA.RGBAAt(x, y)
+
+			difference := maxDifference(gotPixel, wantPixel)
+			if difference > comparison.MaxDifference {
+				comparison.MaxDifference = difference
+			}
+
+			if difference > tolerance {
+				comparison.Mismatched++
+				comparison.Diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+			} else {
+				// Matching pixels are drawn dimmed, so the mismatched ones stand out
+				grey := uint8((uint32(wantPixel.R) + uint32(wantPixel.G) + uint32(wantPixel.B)) / 6)
+				comparison.Diff.SetRGBA(x, y, color.RGBA{grey, grey, grey, 255})
+			}
+		}
+	}
+
+	return comparison, nil
+}
+
+// Returns the largest difference between the channels of two colours
+func maxDifference(a, b color.RGBA) uint8 {
+	var largest uint8
+	for _, channels := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		difference := channels[0] - channels[1]
+		if channels[1] > channels[0] {
+			difference = channels[1] - channels[0]
+		}
+
+		if difference > largest {
+			largest = difference
+		}
+	}
+
+	return largest
+}
@@ -0,0 +1,148 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"testing"
+)
+
+// Creates an image filled with a colour
+func filled(width, height int, colour color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, colour)
+		}
+	}
+	return img
+}
+
+func TestCompareIdentical(t *testing.T) {
+	img := filled(4, 3, color.RGBA{10, 20, 30, 255})
+
+	comparison, err := Compare(img, img, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if comparison.Mismatched != 0 || comparison.MaxDifference != 0 || comparison.Total != 12 {
+		t.Errorf("got %d/%d mismatched (max difference %d), expected 0/12", comparison.Mismatched, comparison.Total, comparison.MaxDifference)
+	}
+}
+
+func TestCompareTolerance(t *testing.T) {
+	want := filled(2, 2, color.RGBA{100, 100, 100, 255})
+	got := filled(2, 2, color.RGBA{100, 100, 100, 255})
+	got.SetRGBA(0, 0, color.RGBA{104, 100, 100, 255})
+	got.SetRGBA(1, 1, color.RGBA{100, 90, 100, 255})
+
+	comparison, err := Compare(got, want, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if comparison.Mismatched != 1 {
+		t.Errorf("got %d mismatched pixels, expected 1", comparison.Mismatched)
+	}
+	if comparison.MaxDifference != 10 {
+		t.Errorf("got a max difference of %d, expected 10", comparison.MaxDifference)
+	}
+
+	// Only the pixel over the tolerance is marked in the diff
+	if pixel := comparison.Diff.RGBAAt(1, 1); pixel != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("mismatched pixel is %v in the diff, expected red", pixel)
+	}
+	if pixel := comparison.Diff.RGBAAt(0, 0); pixel.R != pixel.G {
+		t.Errorf("pixel within the tolerance is %v in the diff, expected grey", pixel)
+	}
+}
+
+func TestCompareSizeMismatch(t *testing.T) {
+	if _, err := Compare(filled(2, 2, color.RGBA{}), filled(2, 3, color.RGBA{}), 0); err == nil {
+		t.Error("expected an error comparing images of different sizes")
+	}
+}
+
+func TestFlipVertically(t *testing.T) {
+	img := filled(1, 3, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	img.SetRGBA(0, 2, color.RGBA{0, 0, 255, 255})
+
+	FlipVertically(img)
+
+	if pixel := img.RGBAAt(0, 0); pixel.B != 255 {
+		t.Errorf("top pixel is %v after flipping, expected blue", pixel)
+	}
+	if pixel := img.RGBAAt(0, 2); pixel.R != 255 {
+		t.Errorf("bottom pixel is %v after flipping, expected red", pixel)
+	}
+}
+
+func TestSaveAndLoadPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "image.png")
+	img := filled(3, 2, color.RGBA{1, 2, 3, 255})
+
+	if err := SavePNG(path, img); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comparison, err := Compare(loaded, img, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Mismatched != 0 {
+		t.Errorf("loaded image has %d mismatched pixels", comparison.Mismatched)
+	}
+}
+
+func TestDecodePNGAndJPEG(t *testing.T) {
+	img := filled(8, 4, color.RGBA{200, 100, 50, 255})
+
+	var pngData, jpegData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegData, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// JPEG is lossy, so its colours are only compared with a tolerance
+	for _, encoded := range []struct {
+		name      string
+		data      []byte
+		tolerance uint8
+	}{
+		{"png", pngData.Bytes(), 0},
+		{"jpeg", jpegData.Bytes(), 4},
+	} {
+		decoded, err := Decode(bytes.NewReader(encoded.data))
+		if err != nil {
+			t.Errorf("%s: %v", encoded.name, err)
+			continue
+		}
+
+		comparison, err := Compare(decoded, img, encoded.tolerance)
+		if err != nil {
+			t.Errorf("%s: %v", encoded.name, err)
+			continue
+		}
+		if comparison.Mismatched != 0 {
+			t.Errorf("%s: decoded image has %d mismatched pixels (max difference %d)", encoded.name, comparison.Mismatched, comparison.MaxDifference)
+		}
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	if _, err := Decode(bytes.NewReader([]byte("not an image"))); err == nil {
+		t.Error("expected an error decoding data that isn't an image")
+	}
+}
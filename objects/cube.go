@@ -1,66 +1,90 @@
 package objects
 
 import (
+	"../gpu"
+
 	"github.com/go-gl/gl/all-core/gl"
-	"github.com/go-gl/mathgl/mgl32"
 )
 
 type Cube struct {
 	bufferObject, normalsObject, coloursObject uint32
+	texCoordsObject                            uint32
 	elementBuffer                              uint32
+	vertexArray                                uint32 // Vertex array object with the attributes of the buffers
 
 	DrawMode                                   DrawMode // Defines drawing mode of cube as points, lines or filled polygons
 
 	vertexPositions, vertexColours, normals    *[]float32
+	texCoords                                  []float32 // Generated per face from the positions
 
-	Model                                      mgl32.Mat4
+	Transform
+	Surface
 }
 
+// Creates a cube from a triangle list, if normals is nil flat normals are generated from the positions
+// The texture coordinates are always generated, with the whole texture on each face
 func NewCube(vertexPositions, vertexColours, normals *[]float32) *Cube {
+	if normals == nil {
+		generated := FlatNormals(*vertexPositions)
+		normals = &generated
+	}
+
 	return &Cube{
 		0, 0, 0, // bufferObject, normals, colours
+		0, // texCoords
 		0, // elementBuffer
+		0, // vertexArray
 		DRAW_POLYGONS, // drawmode
 		vertexPositions, vertexColours, normals, // vertexPositions, vertexColours, normals
-		mgl32.Ident4(), // model
+		BoxTexCoords(*vertexPositions), // texCoords
+		NewTransform(), // transform
+		NewSurface(), // surface
 	}
 }
 
 func (cube *Cube) MakeVBO() {
+	// Deletes the buffers of a previous call, so calling it again doesn't leak them
+	cube.Release()
+
 	// Create a vertex buffer object to store vertices for the cube
-	gl.GenBuffers(1, &cube.bufferObject);
+	cube.bufferObject = gpu.GenBuffer("cube positions");
 	gl.BindBuffer(gl.ARRAY_BUFFER, cube.bufferObject);
 	gl.BufferData(gl.ARRAY_BUFFER, len(*cube.vertexPositions) * 4, gl.Ptr(*cube.vertexPositions), gl.STATIC_DRAW);
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
 
 	// Create a vertex buffer object to store vertex colours for the cube
-	gl.GenBuffers(1, &cube.coloursObject);
+	cube.coloursObject = gpu.GenBuffer("cube colours");
 	gl.BindBuffer(gl.ARRAY_BUFFER, cube.coloursObject);
 	gl.BufferData(gl.ARRAY_BUFFER, len(*cube.vertexColours) * 4, gl.Ptr(*cube.vertexColours), gl.STATIC_DRAW);
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
 
 	// Create the normals buffer for the cube
-	gl.GenBuffers(1, &cube.normalsObject);
+	cube.normalsObject = gpu.GenBuffer("cube normals");
 	gl.BindBuffer(gl.ARRAY_BUFFER, cube.normalsObject);
 	gl.BufferData(gl.ARRAY_BUFFER, len(*cube.normals) * 4, gl.Ptr(*cube.normals), gl.STATIC_DRAW);
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
-}
 
-func (cube *Cube) Draw() {
-	/* Bind cube vertices. Note that this is in attribute index 0 */
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.bufferObject)
-	gl.EnableVertexAttribArray(0)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
+	// Create the texture coordinates buffer for the cube
+	cube.texCoordsObject = gpu.GenBuffer("cube texture coordinates");
+	gl.BindBuffer(gl.ARRAY_BUFFER, cube.texCoordsObject);
+	gl.BufferData(gl.ARRAY_BUFFER, len(cube.texCoords) * 4, gl.Ptr(cube.texCoords), gl.STATIC_DRAW);
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0);
 
-	/* Bind cube colours. Note that this is in attribute index 1 */
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.coloursObject)
-	gl.EnableVertexAttribArray(1)
-	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 0, nil)
+	// Positions, colours, normals and texture coordinates are in attribute indices 0, 1, 2 and 3
+	cube.vertexArray = gpu.NewVertexArray("cube", gpu.VertexLayout{
+		Attributes: []gpu.VertexAttribute{
+			gpu.FloatAttribute(0, cube.bufferObject, 3), // positions
+			gpu.FloatAttribute(1, cube.coloursObject, 4), // colours
+			gpu.FloatAttribute(2, cube.normalsObject, 3), // normals
+			gpu.FloatAttribute(3, cube.texCoordsObject, 2), // texture coordinates
+		},
+		Elements: 0, // not indexed
+	})
+}
 
-	/* Bind cube normals. Note that this is in attribute index 2 */
-	gl.EnableVertexAttribArray(2)
-	gl.BindBuffer(gl.ARRAY_BUFFER, cube.normalsObject)
-	gl.VertexAttribPointer(2, 3, gl.FLOAT, false, 0, nil)
+func (cube *Cube) Draw() {
+	/* Bind the cube vertex array, it has all the attributes */
+	gl.BindVertexArray(cube.vertexArray)
 
 	// Enable this line to show model in wireframe
 	if cube.DrawMode == DRAW_LINES {
@@ -69,26 +93,23 @@ func (cube *Cube) Draw() {
 		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
 	}
 
-	/* Draw our cube*/
+	/* Draw our cube, with the same number of vertices as points or triangles */
+	vertexCount := int32(len(*cube.vertexPositions) / 3)
 	if cube.DrawMode == DRAW_POINTS {
-		gl.DrawArrays(gl.POINTS, 0, int32(32))
+		gpu.DrawArrays(gl.POINTS, 0, vertexCount)
 	} else {
-		gl.DrawArrays(gl.TRIANGLES, 0, 36)
+		gpu.DrawArrays(gl.TRIANGLES, 0, vertexCount)
 	}
-}
-
-func (cube *Cube) ResetModel () {
-	cube.Model = mgl32.Ident4()
-}
-
-func (cube *Cube) Translate (Tx, Ty, Tz float32) {
-	cube.Model = cube.Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
-}
 
-func (cube *Cube) Scale (scaleX, scaleY, scaleZ float32) {
-	cube.Model = cube.Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+	gl.BindVertexArray(0)
 }
 
-func (cube *Cube) Rotate (angle float32, axis mgl32.Vec3) {
-	cube.Model = cube.Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+// Deletes the buffer objects and the vertex array of the cube (MakeVBO creates them again)
+func (cube *Cube) Release() {
+	gpu.DeleteVertexArray(&cube.vertexArray)
+	gpu.DeleteBuffer(&cube.bufferObject)
+	gpu.DeleteBuffer(&cube.coloursObject)
+	gpu.DeleteBuffer(&cube.normalsObject)
+	gpu.DeleteBuffer(&cube.texCoordsObject)
+	gpu.DeleteBuffer(&cube.elementBuffer)
 }
@@ -0,0 +1,77 @@
+package objects
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Anything with a model matrix that can be moved, scaled and rotated
+type Transformable interface {
+	ResetModel()
+	Translate(Tx, Ty, Tz float32)
+	Scale(scaleX, scaleY, scaleZ float32)
+	Rotate(angle float32, axis mgl32.Vec3)
+	GetModel() mgl32.Mat4
+}
+
+// Anything that can upload its geometry to the GPU and draw it
+type Drawable interface {
+	Transformable
+
+	MakeVBO() // Creates the buffer objects (after the GL context is created, calling it again recreates them)
+	Draw()    // Draws the object with the currently bound shader program
+	Release() // Deletes the buffer objects
+
+	GetMaterial() *Material
+	SetMaterial(material *Material)
+}
+
+// Material of an object, embed it in an object to give it a material
+type Surface struct {
+	Material *Material
+}
+
+// Creates a surface with the default material (solid green)
+func NewSurface() Surface {
+	return Surface{
+		NewMaterial("default", mgl32.Vec3{0.0, 1.0, 0.0}), // material
+	}
+}
+
+func (surface *Surface) GetMaterial() *Material {
+	return surface.Material
+}
+
+func (surface *Surface) SetMaterial(material *Material) {
+	surface.Material = material
+}
+
+// Model matrix and the helpers to build it, embed it in an object to make it Transformable
+type Transform struct {
+	Model mgl32.Mat4
+}
+
+func NewTransform() Transform {
+	return Transform{
+		mgl32.Ident4(), // model
+	}
+}
+
+func (transform *Transform) ResetModel() {
+	transform.Model = mgl32.Ident4()
+}
+
+func (transform *Transform) Translate(Tx, Ty, Tz float32) {
+	transform.Model = transform.Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
+}
+
+func (transform *Transform) Scale(scaleX, scaleY, scaleZ float32) {
+	transform.Model = transform.Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+}
+
+func (transform *Transform) Rotate(angle float32, axis mgl32.Vec3) {
+	transform.Model = transform.Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+}
+
+func (transform *Transform) GetModel() mgl32.Mat4 {
+	return transform.Model
+}
@@ -2,6 +2,9 @@ package objects
 
 type DrawMode int32
 type ColorMode int32
+type ShadeMode int32
+type SpecularMode int32
+type LightType int32
 
 const (
 	_ = iota // ignore first value by assigning to blank identifier
@@ -16,6 +19,25 @@ const (
 	COLOR_SOLID
 )
 
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	SHADE_PER_VERTEX ShadeMode = 0 + iota // Gouraud shading, the lighting is calculated in the vertex shader
+	SHADE_PER_FRAGMENT // Phong shading, the lighting is calculated in the fragment shader
+)
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	SPECULAR_PHONG SpecularMode = 0 + iota // Specular term from the reflected light vector
+	SPECULAR_BLINN_PHONG // Specular term from the half vector between the light and the eye
+)
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	LIGHT_DIRECTIONAL LightType = 0 + iota // Infinitely far away, lights everything from the same direction
+	LIGHT_POINT // Lights in every direction from a position, attenuated by distance
+	LIGHT_SPOT // Point light limited to a cone
+)
+
 
 var drawModeNames = [...]string{
 	"_",
@@ -25,14 +47,55 @@ var drawModeNames = [...]string{
 }
 
 var colorModeNames = [...]string{
+	"_",
 	"Color per side",
 	"Solid Color",
 }
 
+var shadeModeNames = [...]string{
+	"_",
+	"Per Vertex (Gouraud)",
+	"Per Fragment (Phong)",
+}
+
+var specularModeNames = [...]string{
+	"_",
+	"Phong",
+	"Blinn-Phong",
+}
+
+var lightTypeNames = [...]string{
+	"_",
+	"Directional",
+	"Point",
+	"Spot",
+}
+
+// Returns the next drawing mode, going back to the first one after the last
+func (drawMode DrawMode) Next() DrawMode {
+	if drawMode >= DRAW_POLYGONS {
+		return DRAW_POINTS
+	}
+
+	return drawMode + 1
+}
+
 func (drawMode DrawMode) String() string {
 	return drawModeNames[drawMode]
 }
 
 func (colorMode ColorMode) String() string {
 	return colorModeNames[colorMode]
-}
\ No newline at end of file
+}
+
+func (shadeMode ShadeMode) String() string {
+	return shadeModeNames[shadeMode]
+}
+
+func (specularMode SpecularMode) String() string {
+	return specularModeNames[specularMode]
+}
+
+func (lightType LightType) String() string {
+	return lightTypeNames[lightType]
+}
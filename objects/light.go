@@ -0,0 +1,201 @@
+package objects
+
+import (
+	"fmt"
+	"math"
+
+	"../gpu"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Most lights the shaders can use at once (MAX_LIGHTS in the shaders)
+const MAX_LIGHTS = 8
+
+// Defines a light source and the colour it contributes to each term of the lighting equation
+type Light struct {
+	Type                       LightType
+
+	Position                   mgl32.Vec4 // Position in world space (w = 0 for directional lights, the direction towards the light)
+	Direction                  mgl32.Vec3 // Direction a spotlight points at, in world space
+
+	Ambient, Diffuse, Specular mgl32.Vec3 // Colour of each of the light components
+
+	Attenuation                mgl32.Vec3 // Constant, linear and quadratic attenuation factors
+	InnerCone, OuterCone       float32    // Spotlight cone angles (degrees from the direction), the light fades out between them
+
+	Enabled                    bool
+}
+
+// Locations of the light uniforms in a shader program
+type LightUniforms struct {
+	count                                         int32
+	kind, position, direction                     [MAX_LIGHTS]int32
+	ambient, diffuse, specular, attenuation, cone [MAX_LIGHTS]int32
+}
+
+// Creates a point light, or a directional light if the w of the position is 0
+func NewLight(position mgl32.Vec4) *Light {
+	lightType := LIGHT_POINT
+	if position.W() == 0 {
+		lightType = LIGHT_DIRECTIONAL
+	}
+
+	return &Light{
+		lightType, // type
+		position, // position
+		mgl32.Vec3{0, 0, -1}, // direction
+		mgl32.Vec3{0.2, 0.2, 0.2}, // ambient
+		mgl32.Vec3{1.0, 1.0, 1.0}, // diffuse
+		mgl32.Vec3{1.0, 1.0, 1.0}, // specular
+		mgl32.Vec3{1.0, 0.05, 0.01}, // attenuation (constant, linear, quadratic)
+		15.0, 20.0, // inner cone, outer cone
+		true, // enabled
+	}
+}
+
+// Creates a directional light shining in a direction (like the sun)
+func NewDirectionalLight(direction mgl32.Vec3) *Light {
+	towards := direction.Normalize().Mul(-1)
+	return NewLight(towards.Vec4(0))
+}
+
+// Creates a point light at a position
+func NewPointLight(position mgl32.Vec3) *Light {
+	return NewLight(position.Vec4(1))
+}
+
+// Creates a spotlight at a position pointing in a direction, the cone angles are in degrees
+func NewSpotLight(position, direction mgl32.Vec3, innerCone, outerCone float32) *Light {
+	light := NewLight(position.Vec4(1))
+	light.Type = LIGHT_SPOT
+	light.Direction = direction.Normalize()
+	light.InnerCone, light.OuterCone = innerCone, outerCone
+	return light
+}
+
+// Returns the light position in eye space, which is the space the shaders do the lighting in
+func (light *Light) EyePosition(view mgl32.Mat4) mgl32.Vec4 {
+	return view.Mul4x1(light.Position)
+}
+
+// Returns the direction of a spotlight in eye space
+func (light *Light) EyeDirection(view mgl32.Mat4) mgl32.Vec3 {
+	return view.Mat3().Mul3x1(light.Direction).Normalize()
+}
+
+// Returns the factor the diffuse and specular terms are scaled by at the given distance from the light
+// (the same attenuation shade() calculates in shaders/lighting.glsl)
+func (light *Light) AttenuationAt(distance float32) float32 {
+	// Directional lights are infinitely far away, so they are not attenuated
+	if light.Type == LIGHT_DIRECTIONAL {
+		return 1.0
+	}
+
+	return 1.0 / (light.Attenuation[0] + light.Attenuation[1] * distance + light.Attenuation[2] * distance * distance)
+}
+
+// Returns the factor a spotlight scales the light by at a point (in world space):
+// 1 inside the inner cone, 0 outside the outer cone and a smooth fade between them (like shade() in shaders/lighting.glsl).
+// If both cones are the same the light has a hard edge, and a point at the light's position is fully lit
+func (light *Light) ConeAt(point mgl32.Vec3) float32 {
+	if light.Type != LIGHT_SPOT {
+		return 1.0
+	}
+
+	toPoint := point.Sub(light.Position.Vec3())
+	if toPoint.Len() == 0 {
+		return 1.0
+	}
+
+	cosAngle := toPoint.Normalize().Dot(light.Direction.Normalize())
+	cosInner, cosOuter := light.coneCosines()
+	return smoothstep(cosOuter, cosInner, cosAngle)
+}
+
+func (light *Light) Translate(Tx, Ty, Tz float32) {
+	light.Position = light.Position.Add(mgl32.Vec4{Tx, Ty, Tz, 0})
+}
+
+// Cosines of the cone angles, the shaders compare them with the cosine of the angle to the spotlight direction
+func (light *Light) coneCosines() (float32, float32) {
+	inner := float64(mgl32.DegToRad(light.InnerCone))
+	outer := float64(mgl32.DegToRad(light.OuterCone))
+	return float32(math.Cos(inner)), float32(math.Cos(outer))
+}
+
+// Same as smoothstep in GLSL, except that when edge0 isn't below edge1 (undefined in GLSL) it is step(edge0, x)
+// like the shaders do for a spotlight with the same inner and outer cones
+func smoothstep(edge0, edge1, x float32) float32 {
+	if edge0 >= edge1 {
+		if x < edge0 {
+			return 0
+		}
+		return 1
+	}
+
+	t := mgl32.Clamp((x - edge0) / (edge1 - edge0), 0.0, 1.0)
+	return t * t * (3.0 - 2.0 * t)
+}
+
+// Calculates the matrix used to transform normals into eye space (the inverse transpose of the model-view matrix)
+func NormalMatrix(model, view mgl32.Mat4) mgl32.Mat3 {
+	return view.Mul4(model).Mat3().Inv().Transpose()
+}
+
+// Finds the light uniforms of a shader program (the lights array and numlights)
+func NewLightUniforms(shader *gpu.Shader) LightUniforms {
+	var uniforms LightUniforms
+	uniforms.count = shader.Uniform("numlights")
+
+	location := func(index int, field string) int32 {
+		return shader.Uniform(fmt.Sprintf("lights[%d].%s", index, field))
+	}
+
+	for i := 0; i < MAX_LIGHTS; i++ {
+		uniforms.kind[i] = location(i, "kind")
+		uniforms.position[i] = location(i, "position")
+		uniforms.direction[i] = location(i, "direction")
+		uniforms.ambient[i] = location(i, "ambient")
+		uniforms.diffuse[i] = location(i, "diffuse")
+		uniforms.specular[i] = location(i, "specular")
+		uniforms.attenuation[i] = location(i, "attenuation")
+		uniforms.cone[i] = location(i, "cone")
+	}
+
+	return uniforms
+}
+
+//
+// Upload
+// Sends the enabled lights to the shader program in use, with their positions and directions in eye space
+// (only the first MAX_LIGHTS enabled lights are used)
+//
+// @param lights ([]*Light) the lights
+// @param view (mgl32.Mat4) the view matrix
+//
+func (uniforms LightUniforms) Upload(lights []*Light, view mgl32.Mat4) {
+	count := 0
+	for _, light := range lights {
+		if !light.Enabled || count == MAX_LIGHTS {
+			continue
+		}
+
+		position := light.EyePosition(view)
+		direction := light.EyeDirection(view)
+		cosInner, cosOuter := light.coneCosines()
+
+		gl.Uniform1ui(uniforms.kind[count], uint32(light.Type))
+		gl.Uniform4fv(uniforms.position[count], 1, &position[0])
+		gl.Uniform3fv(uniforms.direction[count], 1, &direction[0])
+		gl.Uniform3fv(uniforms.ambient[count], 1, &light.Ambient[0])
+		gl.Uniform3fv(uniforms.diffuse[count], 1, &light.Diffuse[0])
+		gl.Uniform3fv(uniforms.specular[count], 1, &light.Specular[0])
+		gl.Uniform3fv(uniforms.attenuation[count], 1, &light.Attenuation[0])
+		gl.Uniform2f(uniforms.cone[count], cosInner, cosOuter)
+		count++
+	}
+
+	gl.Uniform1i(uniforms.count, int32(count))
+}
@@ -0,0 +1,118 @@
+package objects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The shaders calculate the attenuation and the spotlight cone like these tests expect (shade() in shaders/lighting.glsl)
+
+// Returns a point at a distance from the origin, at an angle (in degrees) from -z
+func pointAtAngle(degrees, distance float32) mgl32.Vec3 {
+	radians := float64(mgl32.DegToRad(degrees))
+	return mgl32.Vec3{float32(math.Sin(radians)), 0, -float32(math.Cos(radians))}.Mul(distance)
+}
+
+func TestAttenuationAt(t *testing.T) {
+	light := NewPointLight(mgl32.Vec3{0, 0, 0})
+
+	tests := []struct {
+		distance float32
+		expected float32
+	}{
+		{0, 1},                          // At the light only the constant factor is left
+		{10, 1.0 / (1 + 0.5 + 1)},
+		{100, 1.0 / (1 + 5 + 100)},
+	}
+	for _, test := range tests {
+		if factor := light.AttenuationAt(test.distance); math.Abs(float64(factor - test.expected)) > 1e-6 {
+			t.Errorf("attenuation at %v is %v, expected %v", test.distance, factor, test.expected)
+		}
+	}
+
+	directional := NewDirectionalLight(mgl32.Vec3{0, -1, 0})
+	for _, distance := range []float32{0, 10, 1000} {
+		if factor := directional.AttenuationAt(distance); factor != 1 {
+			t.Errorf("directional light attenuation at %v is %v, expected 1", distance, factor)
+		}
+	}
+}
+
+func TestConeAt(t *testing.T) {
+	spot := NewSpotLight(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 0, -5}, 15, 20)
+
+	tests := []struct {
+		degrees  float32
+		expected float32
+	}{
+		{0, 1},
+		{10, 1},
+		{14.99, 1},  // Inner edge
+		{20.01, 0},  // Outer edge
+		{45, 0},
+		{180, 0},
+	}
+	for _, test := range tests {
+		if factor := spot.ConeAt(pointAtAngle(test.degrees, 3)); math.Abs(float64(factor - test.expected)) > 1e-6 {
+			t.Errorf("cone at %v degrees is %v, expected %v", test.degrees, factor, test.expected)
+		}
+	}
+
+	// Between the edges it fades out, half way between the cosines it is 0.5
+	cosInner, cosOuter := math.Cos(15 * math.Pi / 180), math.Cos(20 * math.Pi / 180)
+	halfway := float32(math.Acos((cosInner + cosOuter) / 2) * 180 / math.Pi)
+	if factor := spot.ConeAt(pointAtAngle(halfway, 2)); math.Abs(float64(factor - 0.5)) > 1e-3 {
+		t.Errorf("cone half way between the edges is %v, expected 0.5", factor)
+	}
+	previous := float32(1)
+	for degrees := float32(15); degrees <= 20; degrees += 0.5 {
+		factor := spot.ConeAt(pointAtAngle(degrees, 2))
+		if factor > previous {
+			t.Errorf("cone at %v degrees is %v, more than %v closer to the inner edge", degrees, factor, previous)
+		}
+		previous = factor
+	}
+
+	// A point at the light's position is lit
+	if factor := spot.ConeAt(mgl32.Vec3{0, 0, 0}); factor != 1 {
+		t.Errorf("cone at the light's position is %v, expected 1", factor)
+	}
+
+	// The same inner and outer cones give a hard edge
+	hard := NewSpotLight(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 0, -1}, 20, 20)
+	if factor := hard.ConeAt(pointAtAngle(19.9, 1)); factor != 1 {
+		t.Errorf("hard cone inside the edge is %v, expected 1", factor)
+	}
+	if factor := hard.ConeAt(pointAtAngle(20.1, 1)); factor != 0 {
+		t.Errorf("hard cone outside the edge is %v, expected 0", factor)
+	}
+
+	// Other lights have no cone
+	if factor := NewPointLight(mgl32.Vec3{0, 0, 0}).ConeAt(pointAtAngle(90, 1)); factor != 1 {
+		t.Errorf("point light cone is %v, expected 1", factor)
+	}
+	if factor := NewDirectionalLight(mgl32.Vec3{0, 0, -1}).ConeAt(pointAtAngle(90, 1)); factor != 1 {
+		t.Errorf("directional light cone is %v, expected 1", factor)
+	}
+}
+
+func TestSmoothstep(t *testing.T) {
+	tests := []struct {
+		edge0, edge1, x float32
+		expected        float32
+	}{
+		{0, 1, -1, 0},
+		{0, 1, 0.5, 0.5},
+		{0, 1, 0.25, 0.15625},
+		{0, 1, 2, 1},
+		{0.5, 0.5, 0.4, 0},  // Same edges, a step
+		{0.5, 0.5, 0.5, 1},
+	}
+	for _, test := range tests {
+		if value := smoothstep(test.edge0, test.edge1, test.x); math.Abs(float64(value - test.expected)) > 1e-6 {
+			t.Errorf("smoothstep(%v, %v, %v) is %v, expected %v", test.edge0, test.edge1, test.x, value, test.expected)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package objects
+
+// A tessellation of a sphere, used from the screen size (in pixels) it is given
+type DetailLevel struct {
+	MinSize     float32 // Smallest diameter on screen (pixels) the level is used at
+	Lats, Longs uint32  // Resolution of the sphere
+}
+
+// Picks a level of detail from the size of an object on screen.
+// A level changes only when the size is past its threshold by the hysteresis margin,
+// so an object that stays around a threshold doesn't flicker between two levels.
+type LevelOfDetail struct {
+	Levels     []DetailLevel // From the least to the most detailed, with increasing MinSize
+	Hysteresis float32       // Fraction of MinSize the size has to go past to change level (0.15 is 15%)
+
+	current    int
+}
+
+// Detail levels of the sphere, from 8x8 for a few pixels to 64x64 when it fills the window
+var SPHERE_DETAIL_LEVELS = []DetailLevel{
+	{0, 8, 8},
+	{60, 12, 12},
+	{120, 20, 20},
+	{250, 32, 32},
+	{500, 48, 48},
+	{900, 64, 64},
+}
+
+// Creates a level of detail selector that starts at the least detailed level
+func NewLevelOfDetail(levels []DetailLevel, hysteresis float32) *LevelOfDetail {
+	return &LevelOfDetail{
+		levels, // levels
+		hysteresis, // hysteresis
+		0, // current
+	}
+}
+
+//
+// Select
+// Picks the level for a size on screen, moving from the current level only past the hysteresis margin
+//
+// @param size (float32) the diameter of the object on screen (pixels)
+//
+// @return level (DetailLevel) the level to use
+//
+func (lod *LevelOfDetail) Select(size float32) DetailLevel {
+	for lod.current + 1 < len(lod.Levels) && size >= lod.Levels[lod.current + 1].MinSize * (1 + lod.Hysteresis) {
+		lod.current++
+	}
+
+	for lod.current > 0 && size < lod.Levels[lod.current].MinSize * (1 - lod.Hysteresis) {
+		lod.current--
+	}
+
+	return lod.Levels[lod.current]
+}
+
+// Returns the index of the level that was selected last
+func (lod *LevelOfDetail) Current() int {
+	return lod.current
+}
@@ -0,0 +1,51 @@
+package objects
+
+import (
+	"testing"
+)
+
+func TestLevelOfDetailHysteresis(t *testing.T) {
+	levels := []DetailLevel{{0, 8, 8}, {100, 16, 16}, {200, 32, 32}}
+	lod := NewLevelOfDetail(levels, 0.1)
+
+	steps := []struct {
+		size  float32
+		level int
+	}{
+		{50, 0},
+		{105, 0},  // Past the threshold, but not by the margin (110)
+		{110, 1},
+		{95, 1},   // Below the threshold, but not by the margin (90)
+		{105, 1},
+		{89, 0},
+		{500, 2},  // Big jumps go through every level at once
+		{185, 2},
+		{10, 0},
+	}
+
+	for i, step := range steps {
+		level := lod.Select(step.size)
+		if lod.Current() != step.level {
+			t.Fatalf("step %d: size %v selected level %d, expected %d", i, step.size, lod.Current(), step.level)
+		}
+		if level != levels[step.level] {
+			t.Errorf("step %d: returned %v, expected %v", i, level, levels[step.level])
+		}
+	}
+}
+
+func TestSphereSetResolution(t *testing.T) {
+	sphere := NewSphere(20, 20)
+
+	if sphere.SetResolution(20, 20) {
+		t.Error("the same resolution was reported as a change")
+	}
+
+	// The buffers aren't made yet, so this doesn't need a GL context
+	if !sphere.SetResolution(1, 0) {
+		t.Error("a new resolution wasn't reported as a change")
+	}
+	if lats, longs := sphere.Resolution(); lats != SPHERE_MIN_LATS || longs != SPHERE_MIN_LONGS {
+		t.Errorf("resolution is %dx%d, expected the minimum %dx%d", lats, longs, SPHERE_MIN_LATS, SPHERE_MIN_LONGS)
+	}
+}
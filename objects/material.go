@@ -0,0 +1,249 @@
+package objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"../gpu"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// How the surface of an object reflects the light
+type Material struct {
+	Name                                 string
+	Ambient, Diffuse, Specular, Emissive mgl32.Vec3 // Reflected ambient, diffuse and specular light, and light given off
+	Shininess                            float32    // Specular exponent, the higher the smaller the highlights
+
+	DiffuseMap                           string       // Path of the diffuse texture (optional)
+	DiffuseTexture                       *gpu.Texture // The diffuse texture, once loaded by LoadMaterialTextures
+}
+
+// Material as it is written in a material library, the colours that are missing keep their defaults
+type materialEntry struct {
+	Ambient    *materialColour `json:"ambient"`
+	Diffuse    *materialColour `json:"diffuse"`
+	Specular   *materialColour `json:"specular"`
+	Emissive   *materialColour `json:"emissive"`
+	Shininess  *float32        `json:"shininess"`
+	DiffuseMap string          `json:"diffuse_map"`
+}
+
+// Colour in a material library, an array of exactly 3 numbers
+type materialColour mgl32.Vec3
+
+// Locations of the material uniforms in a shader program
+type MaterialUniforms struct {
+	ambient, diffuse, specular, emissive, shininess int32
+	diffuseMap, useTexture                          int32
+}
+
+// Creates a material of a colour (used for the ambient and diffuse light) with white highlights
+func NewMaterial(name string, colour mgl32.Vec3) *Material {
+	return &Material{
+		name, // name
+		colour, colour, // ambient, diffuse
+		mgl32.Vec3{1.0, 1.0, 1.0}, // specular
+		mgl32.Vec3{0.0, 0.0, 0.0}, // emissive
+		8.0, // shininess
+		"", nil, // diffuse map, diffuse texture
+	}
+}
+
+// Converts a material read from an MTL file
+func (objMaterial *ObjMaterial) Material() *Material {
+	return &Material{
+		objMaterial.Name, // name
+		objMaterial.Ambient, objMaterial.Diffuse, // ambient, diffuse
+		objMaterial.Specular, objMaterial.Emissive, // specular, emissive
+		objMaterial.Shininess, // shininess
+		objMaterial.DiffuseMap, nil, // diffuse map, diffuse texture
+	}
+}
+
+//
+// Parse Materials
+// Parses a JSON material library, material names mapped to their properties:
+// { "ruby": { "ambient": [0.17, 0.01, 0.01], "diffuse": [0.61, 0.04, 0.04], "specular": [0.73, 0.63, 0.63],
+//             "emissive": [0, 0, 0], "shininess": 76.8, "diffuse_map": "textures/ruby.png" } }
+// Every property is optional, the ambient colour defaults to the diffuse colour.
+//
+// @param reader (io.Reader) the material library
+//
+// @return materials (map[string]*Material) the materials by name
+// @return error (error) the error (if any)
+//
+func ParseMaterials(reader io.Reader) (map[string]*Material, error) {
+	var entries map[string]materialEntry
+	if err := json.NewDecoder(reader).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	materials := make(map[string]*Material, len(entries))
+	for name, entry := range entries {
+		material := NewMaterial(name, mgl32.Vec3{0.8, 0.8, 0.8})
+
+		if entry.Diffuse != nil {
+			material.Diffuse = mgl32.Vec3(*entry.Diffuse)
+			material.Ambient = material.Diffuse
+		}
+		if entry.Ambient != nil {
+			material.Ambient = mgl32.Vec3(*entry.Ambient)
+		}
+		if entry.Specular != nil {
+			material.Specular = mgl32.Vec3(*entry.Specular)
+		}
+		if entry.Emissive != nil {
+			material.Emissive = mgl32.Vec3(*entry.Emissive)
+		}
+		if entry.Shininess != nil {
+			if *entry.Shininess <= 0 {
+				return nil, fmt.Errorf("material %q: shininess has to be positive, got %v", name, *entry.Shininess)
+			}
+			material.Shininess = *entry.Shininess
+		}
+		material.DiffuseMap = entry.DiffuseMap
+
+		materials[name] = material
+	}
+
+	return materials, nil
+}
+
+// Reads a colour, JSON arrays with missing or extra components are not accepted (they would be filled with zeros or cut)
+func (colour *materialColour) UnmarshalJSON(data []byte) error {
+	var components []float32
+	if err := json.Unmarshal(data, &components); err != nil {
+		return err
+	}
+	if len(components) != 3 {
+		return fmt.Errorf("colour %s has %d components, expected 3", data, len(components))
+	}
+
+	copy(colour[:], components)
+	return nil
+}
+
+//
+// Load Materials
+// Reads a JSON material library (see ParseMaterials), the texture paths are relative to the library
+//
+// @param path (string) the path to the material library
+//
+// @return materials (map[string]*Material) the materials by name
+// @return error (error) the error (if any)
+//
+func LoadMaterials(path string) (map[string]*Material, error) {
+	content, err := gpu.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	materials, err := ParseMaterials(strings.NewReader(strings.TrimSuffix(content, "\x00")))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	for _, material := range materials {
+		if material.DiffuseMap != "" && !filepath.IsAbs(material.DiffuseMap) {
+			material.DiffuseMap = filepath.Join(filepath.Dir(path), material.DiffuseMap)
+		}
+	}
+
+	return materials, nil
+}
+
+//
+// Load Material Textures
+// Loads the textures of the materials, materials using the same image share the texture
+//
+// @param materials (map[string]*Material) the materials
+// @param options (gpu.TextureOptions) how the textures are sampled
+//
+// @return error (error) the error (if any)
+//
+func LoadMaterialTextures(materials map[string]*Material, options gpu.TextureOptions) error {
+	textures := make(map[string]*gpu.Texture)
+
+	for _, name := range MaterialNames(materials) {
+		material := materials[name]
+		if material.DiffuseMap == "" || material.DiffuseTexture != nil {
+			continue
+		}
+
+		texture, found := textures[material.DiffuseMap]
+		if !found {
+			var err error
+			if texture, err = gpu.LoadTexture(material.DiffuseMap, options); err != nil {
+				return fmt.Errorf("material %q: %v", name, err)
+			}
+			textures[material.DiffuseMap] = texture
+		}
+
+		material.DiffuseTexture = texture
+	}
+
+	return nil
+}
+
+// Releases the textures of the materials (shared textures are released once)
+func ReleaseMaterialTextures(materials map[string]*Material) {
+	for _, material := range materials {
+		if material.DiffuseTexture != nil {
+			material.DiffuseTexture.Release()
+			material.DiffuseTexture = nil
+		}
+	}
+}
+
+// Returns the names of the materials in alphabetical order
+func MaterialNames(materials map[string]*Material) []string {
+	names := make([]string, 0, len(materials))
+	for name := range materials {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Finds the material uniforms of a shader program
+func NewMaterialUniforms(shader *gpu.Shader) MaterialUniforms {
+	return MaterialUniforms{
+		shader.Uniform("materialambient"), // ambient
+		shader.Uniform("materialdiffuse"), // diffuse
+		shader.Uniform("materialspecular"), // specular
+		shader.Uniform("materialemissive"), // emissive
+		shader.Uniform("materialshininess"), // shininess
+		shader.Uniform("diffusemap"), // diffuse map
+		shader.Uniform("usetexture"), // use texture
+	}
+}
+
+//
+// Upload
+// Sends a material to the shader program in use, its texture is bound to texture unit 0
+//
+// @param material (*Material) the material
+// @param textures (bool) if false the texture of the material isn't used
+//
+func (uniforms MaterialUniforms) Upload(material *Material, textures bool) {
+	gl.Uniform3fv(uniforms.ambient, 1, &material.Ambient[0])
+	gl.Uniform3fv(uniforms.diffuse, 1, &material.Diffuse[0])
+	gl.Uniform3fv(uniforms.specular, 1, &material.Specular[0])
+	gl.Uniform3fv(uniforms.emissive, 1, &material.Emissive[0])
+	gl.Uniform1f(uniforms.shininess, material.Shininess)
+
+	if textures && material.DiffuseTexture != nil {
+		material.DiffuseTexture.Bind(0)
+		gl.Uniform1i(uniforms.diffuseMap, 0)
+		gl.Uniform1i(uniforms.useTexture, 1)
+	} else {
+		gl.Uniform1i(uniforms.useTexture, 0)
+	}
+}
@@ -0,0 +1,66 @@
+package objects
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestParseMaterials(t *testing.T) {
+	materials, err := ParseMaterials(strings.NewReader(`{
+		"empty": {},
+		"red": { "diffuse": [1, 0, 0], "shininess": 32 },
+		"glow": { "ambient": [0.1, 0.1, 0.1], "diffuse": [0, 1, 0], "specular": [0, 0, 0], "emissive": [0.5, 0.5, 0] },
+		"tiles": { "diffuse_map": "textures/checker.png" }
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(materials) != 4 {
+		t.Fatalf("%d materials, expected 4", len(materials))
+	}
+
+	// Every omitted property has its default
+	grey := mgl32.Vec3{0.8, 0.8, 0.8}
+	empty := materials["empty"]
+	if empty.Name != "empty" || empty.Ambient != grey || empty.Diffuse != grey || empty.Specular != (mgl32.Vec3{1, 1, 1}) ||
+		empty.Emissive != (mgl32.Vec3{0, 0, 0}) || empty.Shininess != 8 || empty.DiffuseMap != "" || empty.DiffuseTexture != nil {
+		t.Errorf("material without properties is %+v", empty)
+	}
+
+	// The ambient colour follows the diffuse colour unless it is set
+	red := materials["red"]
+	if red.Diffuse != (mgl32.Vec3{1, 0, 0}) || red.Ambient != red.Diffuse || red.Shininess != 32 {
+		t.Errorf("red is %+v", red)
+	}
+
+	glow := materials["glow"]
+	if glow.Ambient != (mgl32.Vec3{0.1, 0.1, 0.1}) || glow.Diffuse != (mgl32.Vec3{0, 1, 0}) ||
+		glow.Specular != (mgl32.Vec3{0, 0, 0}) || glow.Emissive != (mgl32.Vec3{0.5, 0.5, 0}) {
+		t.Errorf("glow is %+v", glow)
+	}
+
+	// The texture path is kept as it is written (LoadMaterials makes it relative to the library)
+	if tiles := materials["tiles"]; tiles.DiffuseMap != "textures/checker.png" || tiles.Diffuse != grey {
+		t.Errorf("tiles is %+v", tiles)
+	}
+}
+
+func TestParseMaterialsErrors(t *testing.T) {
+	libraries := map[string]string{
+		"colour with 2 components": `{ "red": { "diffuse": [1, 0] } }`,
+		"colour with 4 components": `{ "red": { "specular": [1, 0, 0, 1] } }`,
+		"colour that isn't an array": `{ "red": { "ambient": "red" } }`,
+		"colour with a string": `{ "red": { "emissive": [1, "0", 0] } }`,
+		"zero shininess": `{ "red": { "shininess": 0 } }`,
+		"not an object": `[1, 2, 3]`,
+		"invalid json": `{ "red": `,
+	}
+
+	for name, library := range libraries {
+		if _, err := ParseMaterials(strings.NewReader(library)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
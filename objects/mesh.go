@@ -0,0 +1,89 @@
+package objects
+
+import (
+	"../gpu"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Mesh packed by a MeshBuilder, drawn from one interleaved vertex buffer and an index buffer
+type Mesh struct {
+	vertexBuffer, elementBuffer uint32
+	vertexArray                 uint32 // Vertex array object with the interleaved attributes and the element buffer
+
+	DrawMode                    DrawMode // Defines drawing mode of the mesh as points, lines or filled polygons
+
+	Data                        *MeshData
+
+	Transform
+	Surface
+}
+
+func NewMesh(data *MeshData) *Mesh {
+	return &Mesh{
+		0, 0, // vertexBuffer, elementBuffer
+		0, // vertexArray
+		DRAW_POLYGONS, // drawmode
+		data, // data
+		NewTransform(), // transform
+		NewSurface(), // surface
+	}
+}
+
+func (mesh *Mesh) MakeVBO() {
+	// Deletes the buffers of a previous call, so calling it again doesn't leak them
+	mesh.Release()
+
+	// Create the interleaved vertex buffer
+	mesh.vertexBuffer = gpu.GenBuffer("mesh vertices")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.vertexBuffer)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Vertices) * 4, gl.Ptr(mesh.Data.Vertices), gl.STATIC_DRAW)
+
+	// Generate a buffer for the indices (uploaded through GL_ARRAY_BUFFER, the element buffer binding belongs to the vertex array)
+	mesh.elementBuffer = gpu.GenBuffer("mesh indices")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.elementBuffer)
+	if mesh.Data.IndexType == gl.UNSIGNED_SHORT {
+		gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Indices16) * 2, gl.Ptr(mesh.Data.Indices16), gl.STATIC_DRAW)
+	} else {
+		gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Indices32) * 4, gl.Ptr(mesh.Data.Indices32), gl.STATIC_DRAW)
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	formats := make([]gpu.AttributeFormat, len(mesh.Data.Attributes))
+	for i, attribute := range mesh.Data.Attributes {
+		formats[i] = gpu.AttributeFormat{Location: attribute.Location, Components: int32(attribute.Components)}
+	}
+
+	mesh.vertexArray = gpu.NewVertexArray("mesh", gpu.VertexLayout{
+		Attributes: gpu.Interleaved(mesh.vertexBuffer, formats...), // interleaved attributes
+		Elements: mesh.elementBuffer, // indices
+	})
+}
+
+func (mesh *Mesh) Draw() {
+	/* Bind the mesh vertex array, it has all the attributes and the indices */
+	gl.BindVertexArray(mesh.vertexArray)
+
+	gl.PointSize(3.0)
+
+	if mesh.DrawMode == DRAW_LINES {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	} else {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+
+	if mesh.DrawMode == DRAW_POINTS {
+		gpu.DrawArrays(gl.POINTS, 0, int32(mesh.Data.VertexCount()))
+	} else {
+		gpu.DrawElements(mesh.Data.Primitive, int32(mesh.Data.IndexCount()), mesh.Data.IndexType, nil)
+	}
+
+	gl.BindVertexArray(0)
+}
+
+// Deletes the buffer objects and the vertex array of the mesh (MakeVBO creates them again)
+func (mesh *Mesh) Release() {
+	gpu.DeleteVertexArray(&mesh.vertexArray)
+	gpu.DeleteBuffer(&mesh.vertexBuffer)
+	gpu.DeleteBuffer(&mesh.elementBuffer)
+}
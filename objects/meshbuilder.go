@@ -0,0 +1,208 @@
+package objects
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Per-vertex attribute of a mesh: its name, the attribute index in the shaders and its number of floats
+type MeshAttribute struct {
+	Name       string
+	Location   uint32
+	Components int
+}
+
+// Attributes of the basic shaders (layout(location = N) in basic.vert)
+var (
+	ATTRIBUTE_POSITION = MeshAttribute{"position", 0, 3}
+	ATTRIBUTE_COLOUR   = MeshAttribute{"colour", 1, 4}
+	ATTRIBUTE_NORMAL   = MeshAttribute{"normal", 2, 3}
+	ATTRIBUTE_TEXCOORD = MeshAttribute{"texcoord", 3, 2}
+)
+
+// Builds an indexed mesh one vertex at a time, vertices with the same values in every attribute are stored once
+type MeshBuilder struct {
+	Attributes []MeshAttribute
+	Primitive  uint32 // How the indices are drawn (gl.TRIANGLES by default)
+
+	vertices   []float32         // Interleaved attributes of the unique vertices
+	indices    []uint32
+	lookup     map[string]uint32 // Index of each unique vertex, by the bits of its values
+	stride     int               // Floats per vertex
+}
+
+// Mesh packed for the GPU: one interleaved vertex buffer and an index buffer
+type MeshData struct {
+	Attributes []MeshAttribute
+	Primitive  uint32
+
+	Vertices   []float32 // Attributes of each vertex next to each other, in the order of Attributes
+	Stride     int       // Floats per vertex
+
+	IndexType  uint32   // gl.UNSIGNED_SHORT if every index fits in 16 bits, gl.UNSIGNED_INT otherwise
+	Indices16  []uint16 // The indices, if IndexType is gl.UNSIGNED_SHORT
+	Indices32  []uint32 // The indices, if IndexType is gl.UNSIGNED_INT
+}
+
+// Creates a mesh builder for vertices with the given attributes (in the order they are interleaved)
+func NewMeshBuilder(attributes ...MeshAttribute) *MeshBuilder {
+	stride := 0
+	for _, attribute := range attributes {
+		stride += attribute.Components
+	}
+
+	return &MeshBuilder{
+		attributes, // attributes
+		gl.TRIANGLES, // primitive
+		nil, nil, // vertices, indices
+		make(map[string]uint32), // lookup
+		stride, // stride
+	}
+}
+
+//
+// Add Vertex
+// Adds a vertex (if there isn't one with the same values already) and returns its index
+//
+// @param values (...[]float32) the values of each attribute, in the order of the attributes of the builder
+//
+// @return index (uint32) the index of the vertex
+// @return error (error) the error (if the number of attributes or of their components doesn't match)
+//
+func (builder *MeshBuilder) AddVertex(values ...[]float32) (uint32, error) {
+	if len(values) != len(builder.Attributes) {
+		return 0, fmt.Errorf("vertex has %d attributes, expected %d", len(values), len(builder.Attributes))
+	}
+
+	vertex := make([]float32, 0, builder.stride)
+	for i, attribute := range builder.Attributes {
+		if len(values[i]) != attribute.Components {
+			return 0, fmt.Errorf("attribute %q has %d components, expected %d", attribute.Name, len(values[i]), attribute.Components)
+		}
+		vertex = append(vertex, values[i]...)
+	}
+
+	key := vertexKey(vertex)
+	if index, found := builder.lookup[key]; found {
+		return index, nil
+	}
+
+	index := uint32(builder.VertexCount())
+	builder.vertices = append(builder.vertices, vertex...)
+	builder.lookup[key] = index
+	return index, nil
+}
+
+// Adds indices to draw, indices of vertices that haven't been added are an error when the mesh is packed
+func (builder *MeshBuilder) AddIndices(indices ...uint32) {
+	builder.indices = append(builder.indices, indices...)
+}
+
+// Adds a triangle from the indices of its vertices (counterclockwise is the front face)
+func (builder *MeshBuilder) AddTriangle(a, b, c uint32) {
+	builder.AddIndices(a, b, c)
+}
+
+// Returns the number of unique vertices added
+func (builder *MeshBuilder) VertexCount() int {
+	if builder.stride == 0 {
+		return 0
+	}
+
+	return len(builder.vertices) / builder.stride
+}
+
+//
+// Pack
+// Packs the vertices and indices for the GPU (the indices use 16 bits if there are 65536 vertices or less)
+//
+// @return data (*MeshData) the packed mesh
+// @return error (error) the error (if an index refers to a vertex that wasn't added)
+//
+func (builder *MeshBuilder) Pack() (*MeshData, error) {
+	vertexCount := builder.VertexCount()
+	for i, index := range builder.indices {
+		if int(index) >= vertexCount {
+			return nil, fmt.Errorf("index %d is %d, but there are %d vertices", i, index, vertexCount)
+		}
+	}
+
+	data := &MeshData{
+		builder.Attributes, // attributes
+		builder.Primitive, // primitive
+		append([]float32(nil), builder.vertices...), builder.stride, // vertices, stride
+		gl.UNSIGNED_INT, nil, nil, // index type, 16 and 32 bit indices
+	}
+
+	if vertexCount <= math.MaxUint16 + 1 {
+		data.IndexType = gl.UNSIGNED_SHORT
+		data.Indices16 = make([]uint16, len(builder.indices))
+		for i, index := range builder.indices {
+			data.Indices16[i] = uint16(index)
+		}
+	} else {
+		data.Indices32 = append([]uint32(nil), builder.indices...)
+	}
+
+	return data, nil
+}
+
+//
+// Build
+// Packs the mesh and creates a drawable with it (its buffers still have to be created with MakeVBO)
+//
+// @return mesh (*Mesh) the mesh
+// @return error (error) the error (if an index refers to a vertex that wasn't added)
+//
+func (builder *MeshBuilder) Build() (*Mesh, error) {
+	data, err := builder.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMesh(data), nil
+}
+
+// Returns the number of vertices of the packed mesh
+func (data *MeshData) VertexCount() int {
+	if data.Stride == 0 {
+		return 0
+	}
+
+	return len(data.Vertices) / data.Stride
+}
+
+// Returns the number of indices of the packed mesh
+func (data *MeshData) IndexCount() int {
+	if data.IndexType == gl.UNSIGNED_SHORT {
+		return len(data.Indices16)
+	}
+
+	return len(data.Indices32)
+}
+
+// Returns the values of an attribute of a vertex of the packed mesh (nil if the mesh doesn't have the attribute)
+func (data *MeshData) Attribute(vertex int, name string) []float32 {
+	offset := vertex * data.Stride
+	for _, attribute := range data.Attributes {
+		if attribute.Name == name {
+			return data.Vertices[offset:offset + attribute.Components]
+		}
+		offset += attribute.Components
+	}
+
+	return nil
+}
+
+// Key of a vertex for the lookup of identical vertices (the exact bits, so 0 and -0 are different vertices)
+func vertexKey(vertex []float32) string {
+	key := make([]byte, len(vertex) * 4)
+	for i, value := range vertex {
+		binary.LittleEndian.PutUint32(key[i * 4:], math.Float32bits(value))
+	}
+
+	return string(key)
+}
@@ -0,0 +1,145 @@
+package objects
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Adds a vertex and fails the test if the builder rejects it
+func mustAddVertex(t *testing.T, builder *MeshBuilder, values ...[]float32) uint32 {
+	index, err := builder.AddVertex(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
+
+func TestMeshBuilderInterleaves(t *testing.T) {
+	builder := NewMeshBuilder(ATTRIBUTE_POSITION, ATTRIBUTE_NORMAL, ATTRIBUTE_TEXCOORD)
+
+	a := mustAddVertex(t, builder, []float32{0, 0, 0}, []float32{0, 0, 1}, []float32{0, 0})
+	b := mustAddVertex(t, builder, []float32{1, 0, 0}, []float32{0, 0, 1}, []float32{1, 0})
+	c := mustAddVertex(t, builder, []float32{0, 1, 0}, []float32{0, 0, 1}, []float32{0, 1})
+	builder.AddTriangle(a, b, c)
+
+	data, err := builder.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Stride != 8 {
+		t.Errorf("stride is %d, expected 8", data.Stride)
+	}
+
+	expected := []float32{
+		0, 0, 0, 0, 0, 1, 0, 0,
+		1, 0, 0, 0, 0, 1, 1, 0,
+		0, 1, 0, 0, 0, 1, 0, 1,
+	}
+	if len(data.Vertices) != len(expected) {
+		t.Fatalf("vertices %v, expected %v", data.Vertices, expected)
+	}
+	for i := range expected {
+		if data.Vertices[i] != expected[i] {
+			t.Fatalf("vertices %v, expected %v", data.Vertices, expected)
+		}
+	}
+
+	if texcoord := data.Attribute(1, "texcoord"); len(texcoord) != 2 || texcoord[0] != 1 || texcoord[1] != 0 {
+		t.Errorf("texcoord of vertex 1 is %v, expected [1 0]", texcoord)
+	}
+	if colour := data.Attribute(0, "colour"); colour != nil {
+		t.Errorf("the mesh has no colours, got %v", colour)
+	}
+}
+
+func TestMeshBuilderDeduplicates(t *testing.T) {
+	builder := NewMeshBuilder(ATTRIBUTE_POSITION, ATTRIBUTE_NORMAL)
+
+	// Two triangles of a quad share two vertices
+	quad := [][]float32{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	for _, position := range quad {
+		builder.AddIndices(mustAddVertex(t, builder, position, []float32{0, 0, 1}))
+	}
+
+	// Same position with another normal is another vertex (like the corners of a cube)
+	mustAddVertex(t, builder, []float32{0, 0, 0}, []float32{1, 0, 0})
+
+	if count := builder.VertexCount(); count != 5 {
+		t.Errorf("%d vertices, expected 5", count)
+	}
+
+	data, err := builder.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []uint16{0, 1, 2, 0, 2, 3}
+	if data.IndexCount() != len(expected) {
+		t.Fatalf("indices %v, expected %v", data.Indices16, expected)
+	}
+	for i := range expected {
+		if data.Indices16[i] != expected[i] {
+			t.Fatalf("indices %v, expected %v", data.Indices16, expected)
+		}
+	}
+}
+
+func TestMeshBuilderIndexType(t *testing.T) {
+	sizes := []struct {
+		vertices  int
+		indexType uint32
+	}{
+		{3, gl.UNSIGNED_SHORT},
+		{65536, gl.UNSIGNED_SHORT},
+		{65537, gl.UNSIGNED_INT},
+	}
+
+	for _, size := range sizes {
+		builder := NewMeshBuilder(MeshAttribute{"id", 0, 1})
+		for i := 0; i < size.vertices; i++ {
+			builder.AddIndices(mustAddVertex(t, builder, []float32{float32(i)}))
+		}
+
+		data, err := builder.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data.IndexType != size.indexType {
+			t.Errorf("%d vertices: index type 0x%x, expected 0x%x", size.vertices, data.IndexType, size.indexType)
+		}
+		if data.IndexCount() != size.vertices || data.VertexCount() != size.vertices {
+			t.Errorf("%d vertices: packed %d vertices and %d indices", size.vertices, data.VertexCount(), data.IndexCount())
+		}
+
+		last := size.vertices - 1
+		if data.IndexType == gl.UNSIGNED_SHORT && int(data.Indices16[last]) != last {
+			t.Errorf("%d vertices: last index is %d", size.vertices, data.Indices16[last])
+		}
+		if data.IndexType == gl.UNSIGNED_INT && int(data.Indices32[last]) != last {
+			t.Errorf("%d vertices: last index is %d", size.vertices, data.Indices32[last])
+		}
+	}
+}
+
+func TestMeshBuilderErrors(t *testing.T) {
+	builder := NewMeshBuilder(ATTRIBUTE_POSITION, ATTRIBUTE_TEXCOORD)
+
+	if _, err := builder.AddVertex([]float32{0, 0, 0}); err == nil {
+		t.Error("expected an error for a missing attribute")
+	}
+	if _, err := builder.AddVertex([]float32{0, 0, 0}, []float32{0, 0, 0}); err == nil {
+		t.Error("expected an error for a texcoord with 3 components")
+	}
+	if builder.VertexCount() != 0 {
+		t.Errorf("rejected vertices were added, %d vertices", builder.VertexCount())
+	}
+
+	mustAddVertex(t, builder, []float32{0, 0, 0}, []float32{0, 0})
+	builder.AddTriangle(0, 0, 1)
+	if _, err := builder.Pack(); err == nil {
+		t.Error("expected an error for an index without a vertex")
+	}
+}
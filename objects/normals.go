@@ -0,0 +1,121 @@
+package objects
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Normal generation for triangle meshes.
+// Positions are packed as x, y, z triples and triangles are expected to be wound counter-clockwise
+// (the OpenGL default for front faces), meshes wound the other way can use FlipNormals on the result.
+
+// Returns the vertex at the given index of a packed position array
+func vertexAt(positions []float32, index uint32) mgl32.Vec3 {
+	return mgl32.Vec3{positions[index * 3], positions[index * 3 + 1], positions[index * 3 + 2]}
+}
+
+// Returns the indices of the triangle list, generating them when the mesh is not indexed
+func triangleIndices(positions []float32, indices []uint32) []uint32 {
+	if indices != nil {
+		return indices
+	}
+
+	var i uint32
+	numVertices := uint32(len(positions) / 3)
+	indices = make([]uint32, numVertices)
+	for i = 0; i < numVertices; i++ {
+		indices[i] = i
+	}
+
+	return indices
+}
+
+// Calculates the (not normalized) normal of a triangle, its length is twice the area of the triangle
+func faceNormal(a, b, c mgl32.Vec3) mgl32.Vec3 {
+	return b.Sub(a).Cross(c.Sub(a))
+}
+
+// Calculates one unit normal per triangle of a triangle list.
+// If indices is nil, every three consecutive positions are a triangle.
+func FaceNormals(positions []float32, indices []uint32) []float32 {
+	indices = triangleIndices(positions, indices)
+	normals := make([]float32, (len(indices) / 3) * 3)
+
+	for i := 0; i + 2 < len(indices); i += 3 {
+		normal := faceNormal(
+			vertexAt(positions, indices[i]),
+			vertexAt(positions, indices[i + 1]),
+			vertexAt(positions, indices[i + 2]),
+		)
+
+		if normal.Len() > 0 {
+			normal = normal.Normalize()
+		}
+
+		copy(normals[i:i + 3], normal[:])
+	}
+
+	return normals
+}
+
+// Calculates per-vertex normals for a non-indexed triangle list, where every vertex of a triangle
+// gets the normal of its face (this gives the faceted look of the cube).
+func FlatNormals(positions []float32) []float32 {
+	faceNormals := FaceNormals(positions, nil)
+	normals := make([]float32, len(positions))
+
+	for face := 0; face * 3 < len(faceNormals); face++ {
+		for corner := 0; corner < 3; corner++ {
+			copy(normals[(face * 3 + corner) * 3:], faceNormals[face * 3:face * 3 + 3])
+		}
+	}
+
+	return normals
+}
+
+// Calculates smooth per-vertex normals for an indexed triangle list.
+// Each vertex normal is the sum of the normals of the faces sharing it, weighted by the area of each face,
+// so small sliver triangles don't bend the normals of a mesh. If indices is nil, every three consecutive
+// positions are a triangle.
+func SmoothNormals(positions []float32, indices []uint32) []float32 {
+	indices = triangleIndices(positions, indices)
+	normals := make([]float32, len(positions))
+
+	for i := 0; i + 2 < len(indices); i += 3 {
+		// Not normalizing the face normal weights it by the area of the triangle
+		normal := faceNormal(
+			vertexAt(positions, indices[i]),
+			vertexAt(positions, indices[i + 1]),
+			vertexAt(positions, indices[i + 2]),
+		)
+
+		for corner := 0; corner < 3; corner++ {
+			index := indices[i + corner] * 3
+			normals[index] += normal[0]
+			normals[index + 1] += normal[1]
+			normals[index + 2] += normal[2]
+		}
+	}
+
+	NormalizeNormals(normals)
+	return normals
+}
+
+// Normalizes every normal of a packed normal array, leaving zero length normals untouched
+func NormalizeNormals(normals []float32) {
+	for i := 0; i + 2 < len(normals); i += 3 {
+		normal := mgl32.Vec3{normals[i], normals[i + 1], normals[i + 2]}
+		if normal.Len() == 0 {
+			continue
+		}
+
+		normal = normal.Normalize()
+		copy(normals[i:i + 3], normal[:])
+	}
+}
+
+// Reverses the direction of every normal of a packed normal array (for meshes wound clockwise)
+func FlipNormals(normals []float32) {
+	for i := range normals {
+		normals[i] = -normals[i]
+	}
+}
@@ -0,0 +1,159 @@
+package objects
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// The triangles of a cube from -1 to 1, wound counterclockwise seen from outside, with the normal each face should have
+func cubeTriangles() ([]float32, []mgl32.Vec3) {
+	var positions []float32
+	var normals []mgl32.Vec3
+
+	for axis := 0; axis < 3; axis++ {
+		for _, side := range []float32{-1, 1} {
+			var normal, u, v mgl32.Vec3
+			normal[axis] = side
+			u[(axis + 1) % 3] = 1
+			v[(axis + 2) % 3] = 1
+
+			// u x v is +normal for the positive side, the negative side swaps them to face outwards
+			if side < 0 {
+				u, v = v, u
+			}
+
+			corner := func(a, b float32) {
+				position := normal.Add(u.Mul(a)).Add(v.Mul(b))
+				positions = append(positions, position[:]...)
+			}
+			corner(-1, -1); corner(1, -1); corner(1, 1)
+			corner(-1, -1); corner(1, 1); corner(-1, 1)
+			normals = append(normals, normal, normal)
+		}
+	}
+
+	return positions, normals
+}
+
+func checkUnitOrZero(t *testing.T, name string, normals []float32) {
+	for i := 0; i + 2 < len(normals); i += 3 {
+		normal := mgl32.Vec3{normals[i], normals[i + 1], normals[i + 2]}
+		for _, value := range normal {
+			if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+				t.Fatalf("%s: normal %d is %v", name, i / 3, normal)
+			}
+		}
+		if length := normal.Len(); length != 0 && math.Abs(float64(length) - 1) > 1e-5 {
+			t.Errorf("%s: normal %d has length %v", name, i / 3, length)
+		}
+	}
+}
+
+func TestFaceNormalsOfACube(t *testing.T) {
+	positions, expected := cubeTriangles()
+
+	normals := FaceNormals(positions, nil)
+	if len(normals) != len(expected) * 3 {
+		t.Fatalf("%d face normals, expected %d", len(normals) / 3, len(expected))
+	}
+	for face, want := range expected {
+		if normal := objVec3(normals, uint32(face)); normal != want {
+			t.Errorf("face %d has normal %v, expected %v", face, normal, want)
+		}
+	}
+
+	// Every corner gets the normal of its face
+	flat := FlatNormals(positions)
+	for vertex := 0; vertex < len(positions) / 3; vertex++ {
+		if normal, want := objVec3(flat, uint32(vertex)), expected[vertex / 3]; normal != want {
+			t.Errorf("flat normal of vertex %d is %v, expected %v", vertex, normal, want)
+		}
+	}
+
+	// With indices, the same triangles reusing the positions of the first face
+	indexed := FaceNormals(positions, []uint32{0, 1, 2, 5, 4, 3})
+	if normal := objVec3(indexed, 0); normal != expected[0] {
+		t.Errorf("indexed face normal is %v, expected %v", normal, expected[0])
+	}
+	if normal := objVec3(indexed, 1); normal != expected[0].Mul(-1) {
+		t.Errorf("reversed indexed face normal is %v, expected %v", normal, expected[0].Mul(-1))
+	}
+}
+
+func TestSmoothNormalsAreAreaWeighted(t *testing.T) {
+	// Vertex 0 is shared by a small triangle facing +z and a triangle facing +x with 4 times its area
+	positions := []float32{
+		0, 0, 0,
+		1, 0, 0,
+		0, 1, 0,
+		0, 2, 0,
+		0, 0, -2,
+	}
+	indices := []uint32{0, 1, 2, 0, 4, 3}
+
+	normals := SmoothNormals(positions, indices)
+	checkUnitOrZero(t, "smooth", normals)
+
+	expected := mgl32.Vec3{4, 0, 1}.Normalize()
+	if normal := objVec3(normals, 0); !normal.ApproxEqual(expected) {
+		t.Errorf("shared normal is %v, expected %v", normal, expected)
+	}
+
+	// The vertices of a single triangle keep its normal
+	if normal := objVec3(normals, 1); !normal.ApproxEqual(mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("normal of vertex 1 is %v, expected [0 0 1]", normal)
+	}
+	if normal := objVec3(normals, 3); !normal.ApproxEqual(mgl32.Vec3{1, 0, 0}) {
+		t.Errorf("normal of vertex 3 is %v, expected [1 0 0]", normal)
+	}
+}
+
+func TestNormalsOfDegenerateTriangles(t *testing.T) {
+	// A triangle with two equal corners and one with its corners on a line
+	positions := []float32{
+		0, 0, 0,
+		0, 0, 0,
+		1, 0, 0,
+		0, 0, 0,
+		1, 1, 1,
+		2, 2, 2,
+	}
+
+	face := FaceNormals(positions, nil)
+	checkUnitOrZero(t, "face", face)
+	for i, value := range face {
+		if value != 0 {
+			t.Fatalf("face normal value %d is %v, expected 0", i, value)
+		}
+	}
+
+	checkUnitOrZero(t, "flat", FlatNormals(positions))
+	checkUnitOrZero(t, "smooth", SmoothNormals(positions, nil))
+
+	// A degenerate triangle doesn't change the normal of a vertex it shares with a real one
+	shared := SmoothNormals([]float32{0, 0, 0, 1, 0, 0, 0, 1, 0, 2, 0, 0}, []uint32{0, 1, 2, 0, 1, 3})
+	if normal := objVec3(shared, 0); normal != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("normal next to a degenerate triangle is %v, expected [0 0 1]", normal)
+	}
+}
+
+func TestNormalizeAndFlipNormals(t *testing.T) {
+	normals := []float32{3, 0, 4, 0, 0, 0, 0, -2, 0}
+
+	NormalizeNormals(normals)
+	expected := []float32{0.6, 0, 0.8, 0, 0, 0, 0, -1, 0}
+	for i := range expected {
+		if math.Abs(float64(normals[i] - expected[i])) > 1e-6 {
+			t.Fatalf("normalized normals are %v, expected %v", normals, expected)
+		}
+	}
+
+	FlipNormals(normals)
+	for i := range expected {
+		if math.Abs(float64(normals[i] + expected[i])) > 1e-6 {
+			t.Fatalf("flipped normals are %v, expected %v", normals, expected)
+		}
+	}
+}
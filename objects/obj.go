@@ -0,0 +1,521 @@
+package objects
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"../gpu"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Wavefront OBJ and MTL parsing.
+// Parsing only reads from an io.Reader and doesn't need a GL context, LoadObj opens the files and creates the mesh.
+
+// Indexed triangle mesh read from an OBJ file, every vertex is a unique position/texcoord/normal/material combination
+type ObjData struct {
+	Positions         []float32 // x, y, z per vertex
+	Colours           []float32 // r, g, b, a per vertex (the diffuse colour of the material of the face, white without one)
+	Normals           []float32 // x, y, z per vertex (smooth normals are generated for vertices without one)
+	TexCoords         []float32 // u, v per vertex (0, 0 for vertices without one)
+	Indices           []uint32  // Three indices per triangle, polygons are triangulated as fans
+
+	Groups            []ObjGroup
+	Materials         map[string]*ObjMaterial
+	MaterialLibraries []string
+}
+
+// Range of triangles of the mesh that belong to the same group and use the same material
+type ObjGroup struct {
+	Name, Material string
+	First, Count   uint32 // Range of the group in the index array
+}
+
+// Material read from an MTL file
+type ObjMaterial struct {
+	Name                                 string
+	Ambient, Diffuse, Specular, Emissive mgl32.Vec3 // Ka, Kd, Ks, Ke
+	Shininess                            float32    // Ns
+	Opacity                              float32    // d (or 1 - Tr)
+	DiffuseMap                           string     // map_Kd
+}
+
+// Opens a material library referenced by an OBJ file
+type MaterialOpener func(name string) (io.ReadCloser, error)
+
+func NewObjMaterial(name string) *ObjMaterial {
+	return &ObjMaterial{
+		name, // name
+		mgl32.Vec3{0.2, 0.2, 0.2}, // ambient
+		mgl32.Vec3{0.8, 0.8, 0.8}, // diffuse
+		mgl32.Vec3{1.0, 1.0, 1.0}, // specular
+		mgl32.Vec3{0.0, 0.0, 0.0}, // emissive
+		8.0, // shininess
+		1.0, // opacity
+		"", // diffuse map
+	}
+}
+
+// Reference to the attributes of a face vertex, also used as the key to deduplicate vertices
+type objVertex struct {
+	position, texCoord, normal int // Zero based, -1 if not present
+	material                   string
+}
+
+// State kept while parsing an OBJ file
+type objParser struct {
+	data                        *ObjData
+	positions, texCoords, normals []float32
+	vertices                    map[objVertex]uint32
+	hasNormal                   []bool
+	group, material             string
+	openMaterial                MaterialOpener
+}
+
+//
+// Parse Obj
+// Parses an OBJ file into an indexed triangle mesh.
+//
+// @param reader (io.Reader) the contents of the OBJ file
+// @param openMaterial (MaterialOpener) opens the material libraries referenced with mtllib (nil to ignore them)
+//
+// @return data (*ObjData) the parsed mesh
+// @return error (error) the error (if any)
+//
+func ParseObj(reader io.Reader, openMaterial MaterialOpener) (*ObjData, error) {
+	parser := &objParser{
+		data: &ObjData{Materials: make(map[string]*ObjMaterial)},
+		vertices: make(map[objVertex]uint32),
+		group: "default",
+		openMaterial: openMaterial,
+	}
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if err := parser.parseLine(scanner.Text()); err != nil {
+			return nil, fmt.Errorf("obj line %d: %v", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	parser.closeGroup()
+	parser.resolveColours()
+	parser.generateMissingNormals()
+	return parser.data, nil
+}
+
+func (parser *objParser) parseLine(line string) error {
+	fields := strings.Fields(stripComment(line))
+	if len(fields) == 0 {
+		return nil
+	}
+
+	arguments := fields[1:]
+	switch fields[0] {
+	case "v":
+		values, err := parseFloats(arguments, 3, 4)
+		if err != nil {
+			return err
+		}
+		parser.positions = append(parser.positions, values[:3]...)
+
+	case "vt":
+		values, err := parseFloats(arguments, 1, 3)
+		if err != nil {
+			return err
+		}
+		values = append(values, 0)
+		parser.texCoords = append(parser.texCoords, values[0], values[1])
+
+	case "vn":
+		values, err := parseFloats(arguments, 3, 3)
+		if err != nil {
+			return err
+		}
+		parser.normals = append(parser.normals, values...)
+
+	case "f":
+		return parser.parseFace(arguments)
+
+	case "g", "o":
+		parser.closeGroup()
+		parser.group = strings.Join(arguments, " ")
+
+	case "usemtl":
+		if len(arguments) != 1 {
+			return fmt.Errorf("usemtl expects a material name")
+		}
+		parser.closeGroup()
+		parser.material = arguments[0]
+
+	case "mtllib":
+		for _, name := range arguments {
+			if err := parser.loadMaterials(name); err != nil {
+				return err
+			}
+		}
+
+	// Smoothing groups, lines, points and free-form geometry are not supported
+	default:
+	}
+
+	return nil
+}
+
+// Parses a polygon and triangulates it as a fan around its first vertex
+func (parser *objParser) parseFace(arguments []string) error {
+	if len(arguments) < 3 {
+		return fmt.Errorf("face needs at least 3 vertices, has %d", len(arguments))
+	}
+
+	indices := make([]uint32, len(arguments))
+	for i, argument := range arguments {
+		index, err := parser.faceVertex(argument)
+		if err != nil {
+			return err
+		}
+		indices[i] = index
+	}
+
+	for i := 1; i + 1 < len(indices); i++ {
+		parser.data.Indices = append(parser.data.Indices, indices[0], indices[i], indices[i + 1])
+	}
+
+	return nil
+}
+
+// Returns the index of a face vertex (v, v/vt, v//vn or v/vt/vn), adding it to the mesh if it is new
+func (parser *objParser) faceVertex(argument string) (uint32, error) {
+	parts := strings.Split(argument, "/")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid face vertex %q", argument)
+	}
+
+	vertex := objVertex{-1, -1, -1, parser.material}
+	var err error
+
+	if vertex.position, err = resolveIndex(parts[0], len(parser.positions) / 3); err != nil {
+		return 0, err
+	}
+	if len(parts) > 1 && parts[1] != "" {
+		if vertex.texCoord, err = resolveIndex(parts[1], len(parser.texCoords) / 2); err != nil {
+			return 0, err
+		}
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if vertex.normal, err = resolveIndex(parts[2], len(parser.normals) / 3); err != nil {
+			return 0, err
+		}
+	}
+
+	if index, found := parser.vertices[vertex]; found {
+		return index, nil
+	}
+
+	data := parser.data
+	index := uint32(len(data.Positions) / 3)
+	parser.vertices[vertex] = index
+
+	data.Positions = append(data.Positions, parser.positions[vertex.position * 3:vertex.position * 3 + 3]...)
+
+	if vertex.texCoord >= 0 {
+		data.TexCoords = append(data.TexCoords, parser.texCoords[vertex.texCoord * 2:vertex.texCoord * 2 + 2]...)
+	} else {
+		data.TexCoords = append(data.TexCoords, 0, 0)
+	}
+
+	if vertex.normal >= 0 {
+		data.Normals = append(data.Normals, parser.normals[vertex.normal * 3:vertex.normal * 3 + 3]...)
+	} else {
+		data.Normals = append(data.Normals, 0, 0, 0)
+	}
+	parser.hasNormal = append(parser.hasNormal, vertex.normal >= 0)
+
+	// The colour of the material is set when the file has been read (its mtllib can come after the usemtl)
+	data.Colours = append(data.Colours, 1.0, 1.0, 1.0, 1.0)
+
+	return index, nil
+}
+
+// Ends the current group, adding it to the mesh if it has any triangles
+func (parser *objParser) closeGroup() {
+	data := parser.data
+
+	var first uint32
+	if len(data.Groups) > 0 {
+		last := data.Groups[len(data.Groups) - 1]
+		first = last.First + last.Count
+	}
+
+	count := uint32(len(data.Indices)) - first
+	if count > 0 {
+		data.Groups = append(data.Groups, ObjGroup{parser.group, parser.material, first, count})
+	}
+}
+
+// Sets the colour of the vertices of each group to the diffuse colour of its material (once every material library is loaded)
+func (parser *objParser) resolveColours() {
+	data := parser.data
+
+	for _, group := range data.Groups {
+		material, found := data.Materials[group.Material]
+		if !found {
+			continue
+		}
+
+		// The material is part of the key of a vertex, so no vertex is shared by groups with different materials
+		colour := material.Diffuse.Vec4(material.Opacity)
+		for _, index := range data.Indices[group.First:group.First + group.Count] {
+			copy(data.Colours[index * 4:index * 4 + 4], colour[:])
+		}
+	}
+}
+
+// Fills the normals missing in the file with smooth normals generated from the faces
+func (parser *objParser) generateMissingNormals() {
+	data := parser.data
+
+	var generated []float32
+	for vertex, hasNormal := range parser.hasNormal {
+		if hasNormal {
+			continue
+		}
+
+		if generated == nil {
+			generated = SmoothNormals(data.Positions, data.Indices)
+		}
+		copy(data.Normals[vertex * 3:vertex * 3 + 3], generated[vertex * 3:vertex * 3 + 3])
+	}
+}
+
+func (parser *objParser) loadMaterials(name string) error {
+	parser.data.MaterialLibraries = append(parser.data.MaterialLibraries, name)
+	if parser.openMaterial == nil {
+		return nil
+	}
+
+	file, err := parser.openMaterial(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	materials, err := ParseMtl(file)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	for materialName, material := range materials {
+		parser.data.Materials[materialName] = material
+	}
+
+	return nil
+}
+
+//
+// Parse Mtl
+// Parses an MTL material library.
+//
+// @param reader (io.Reader) the contents of the MTL file
+//
+// @return materials (map[string]*ObjMaterial) the materials by name
+// @return error (error) the error (if any)
+//
+func ParseMtl(reader io.Reader) (map[string]*ObjMaterial, error) {
+	materials := make(map[string]*ObjMaterial)
+	var material *ObjMaterial
+
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		fields := strings.Fields(stripComment(scanner.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "newmtl" {
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("mtl line %d: newmtl expects a material name", lineNumber)
+			}
+			material = NewObjMaterial(fields[1])
+			materials[material.Name] = material
+			continue
+		}
+
+		if material == nil {
+			return nil, fmt.Errorf("mtl line %d: %s before newmtl", lineNumber, fields[0])
+		}
+
+		if err := parseMaterialLine(material, fields[0], fields[1:]); err != nil {
+			return nil, fmt.Errorf("mtl line %d: %v", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return materials, nil
+}
+
+func parseMaterialLine(material *ObjMaterial, keyword string, arguments []string) error {
+	var colour *mgl32.Vec3
+	switch keyword {
+	case "Ka":
+		colour = &material.Ambient
+	case "Kd":
+		colour = &material.Diffuse
+	case "Ks":
+		colour = &material.Specular
+	case "Ke":
+		colour = &material.Emissive
+
+	case "Ns", "d", "Tr":
+		values, err := parseFloats(arguments, 1, 1)
+		if err != nil {
+			return err
+		}
+
+		if keyword == "Ns" {
+			material.Shininess = values[0]
+		} else if keyword == "d" {
+			material.Opacity = values[0]
+		} else {
+			material.Opacity = 1.0 - values[0]
+		}
+		return nil
+
+	case "map_Kd":
+		// Options can come before the file name, which is always the last argument
+		if len(arguments) == 0 {
+			return fmt.Errorf("map_Kd expects a file name")
+		}
+		material.DiffuseMap = arguments[len(arguments) - 1]
+		return nil
+
+	// Other statements (illumination models, other maps...) are not supported
+	default:
+		return nil
+	}
+
+	values, err := parseFloats(arguments, 3, 3)
+	if err != nil {
+		return err
+	}
+	*colour = mgl32.Vec3{values[0], values[1], values[2]}
+	return nil
+}
+
+//
+// Load Obj
+// Loads an OBJ file (and the material libraries it references, relative to it) into a mesh drawn with one material,
+// files whose faces use more than one material (or some faces none) are not supported.
+// The buffer objects of the mesh still have to be created with MakeVBO.
+//
+// @param path (string) the path to the OBJ file
+//
+// @return mesh (*ObjMesh) the mesh
+// @return error (error) the error (if any)
+//
+func LoadObj(path string) (*ObjMesh, error) {
+	file, err := gpu.OpenAsset(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	directory := filepath.Dir(path)
+	data, err := ParseObj(file, func(name string) (io.ReadCloser, error) {
+		return gpu.OpenAsset(filepath.Join(directory, name))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	// The mesh is drawn with one material, so a file that uses several would lose the specular colours,
+	// shininess and textures of all but one of them (ParseObj still reads every group and material)
+	used := data.UsedMaterials()
+	if len(used) > 1 {
+		return nil, fmt.Errorf("%s: uses %d materials %q, a mesh loaded from an OBJ file can only have one", path, len(used), used)
+	}
+
+	mesh := NewObjMesh(data)
+	if len(used) == 1 {
+		if objMaterial, found := data.Materials[used[0]]; found {
+			material := objMaterial.Material()
+			if material.DiffuseMap != "" && !filepath.IsAbs(material.DiffuseMap) {
+				material.DiffuseMap = filepath.Join(directory, material.DiffuseMap)
+			}
+			mesh.SetMaterial(material)
+		}
+	}
+
+	return mesh, nil
+}
+
+// Returns the names of the materials the faces use (in the order they are first used), "" for faces without a material
+func (data *ObjData) UsedMaterials() []string {
+	var used []string
+	for _, group := range data.Groups {
+		found := false
+		for _, name := range used {
+			found = found || name == group.Material
+		}
+		if !found {
+			used = append(used, group.Material)
+		}
+	}
+	return used
+}
+
+// Removes everything after a # on a line
+func stripComment(line string) string {
+	if comment := strings.IndexByte(line, '#'); comment >= 0 {
+		return line[:comment]
+	}
+	return line
+}
+
+// Parses between min and max float arguments
+func parseFloats(arguments []string, min, max int) ([]float32, error) {
+	if len(arguments) < min || len(arguments) > max {
+		return nil, fmt.Errorf("expected %d to %d values, got %d", min, max, len(arguments))
+	}
+
+	values := make([]float32, len(arguments))
+	for i, argument := range arguments {
+		value, err := strconv.ParseFloat(argument, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", argument)
+		}
+		values[i] = float32(value)
+	}
+
+	return values, nil
+}
+
+// Converts a one based (or negative, relative to the end) OBJ index into a zero based index
+func resolveIndex(argument string, count int) (int, error) {
+	index, err := strconv.Atoi(argument)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", argument)
+	}
+
+	if index < 0 {
+		index += count
+	} else {
+		index--
+	}
+
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index %s out of range (%d defined)", argument, count)
+	}
+
+	return index, nil
+}
@@ -0,0 +1,235 @@
+package objects
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"../gpu"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Parses an OBJ file from a string, with the material libraries in a map by name
+func parseObjString(t *testing.T, source string, libraries map[string]string) *ObjData {
+	data, err := ParseObj(strings.NewReader(source), func(name string) (io.ReadCloser, error) {
+		library, found := libraries[name]
+		if !found {
+			return nil, fmt.Errorf("no material library %q", name)
+		}
+		return io.NopCloser(strings.NewReader(library)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func checkIndices(t *testing.T, got []uint32, want ...uint32) {
+	if len(got) != len(want) {
+		t.Fatalf("indices %v, expected %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("indices %v, expected %v", got, want)
+		}
+	}
+}
+
+func objVec3(values []float32, index uint32) mgl32.Vec3 {
+	return mgl32.Vec3{values[index * 3], values[index * 3 + 1], values[index * 3 + 2]}
+}
+
+const objSquare = `
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+`
+
+func TestObjFanTriangulation(t *testing.T) {
+	quad := parseObjString(t, objSquare + "f 1 2 3 4\n", nil)
+	checkIndices(t, quad.Indices, 0, 1, 2, 0, 2, 3)
+
+	pentagon := parseObjString(t, objSquare + "v -0.5 0.5 0\nf 1 2 3 4 5\n", nil)
+	checkIndices(t, pentagon.Indices, 0, 1, 2, 0, 2, 3, 0, 3, 4)
+}
+
+func TestObjNegativeIndices(t *testing.T) {
+	// -1 is the last vertex defined before the face
+	data := parseObjString(t, objSquare + "f -4 -3 -2\nv 5 5 5\nf -1 -5 -4\n", nil)
+
+	checkIndices(t, data.Indices, 0, 1, 2, 3, 0, 1)
+	if position := objVec3(data.Positions, 3); position != (mgl32.Vec3{5, 5, 5}) {
+		t.Errorf("position of vertex 3 is %v, expected [5 5 5]", position)
+	}
+}
+
+func TestObjVertexForms(t *testing.T) {
+	data := parseObjString(t, objSquare + `
+vt 0.25 0.75
+vn 0 0 -1
+f 1//1 2//1 3//1
+f 1/1 3/1 4/1
+`, nil)
+
+	// v//vn uses the normal of the file and has no texture coordinates
+	if normal := objVec3(data.Normals, 0); normal != (mgl32.Vec3{0, 0, -1}) {
+		t.Errorf("v//vn normal is %v, expected [0 0 -1]", normal)
+	}
+	if data.TexCoords[0] != 0 || data.TexCoords[1] != 0 {
+		t.Errorf("v//vn texture coordinates are %v, expected [0 0]", data.TexCoords[:2])
+	}
+
+	// v/vt is another vertex (same position, no normal), its normal is generated from the face
+	checkIndices(t, data.Indices, 0, 1, 2, 3, 4, 5)
+	if u, v := data.TexCoords[3 * 2], data.TexCoords[3 * 2 + 1]; u != 0.25 || v != 0.75 {
+		t.Errorf("v/vt texture coordinates are [%v %v], expected [0.25 0.75]", u, v)
+	}
+	if normal := objVec3(data.Normals, 3); !normal.ApproxEqual(mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("generated normal is %v, expected [0 0 1]", normal)
+	}
+}
+
+func TestObjGroupsAndMaterials(t *testing.T) {
+	data := parseObjString(t, objSquare + `
+g front
+f 1 2 3
+usemtl red
+f 1 3 4
+g back
+f 3 2 1
+`, nil)
+
+	want := []ObjGroup{
+		{"front", "", 0, 3},
+		{"front", "red", 3, 3},
+		{"back", "red", 6, 3},
+	}
+	if len(data.Groups) != len(want) {
+		t.Fatalf("groups %v, expected %v", data.Groups, want)
+	}
+	for i := range want {
+		if data.Groups[i] != want[i] {
+			t.Errorf("group %d is %v, expected %v", i, data.Groups[i], want[i])
+		}
+	}
+}
+
+func TestObjDeduplicatesVertices(t *testing.T) {
+	// The two triangles of the quad share two corners
+	data := parseObjString(t, objSquare + "vn 0 0 1\nvn 1 0 0\nf 1//1 2//1 3//1\nf 1//1 3//1 4//1\nf 1//2 2//2 3//2\n", nil)
+
+	// The third triangle has the same positions with another normal, so its vertices are new
+	checkIndices(t, data.Indices, 0, 1, 2, 0, 2, 3, 4, 5, 6)
+	if count := len(data.Positions) / 3; count != 7 {
+		t.Errorf("%d vertices, expected 7", count)
+	}
+}
+
+func TestObjMaterialLibrary(t *testing.T) {
+	libraries := map[string]string{
+		"colours.mtl": `
+newmtl red
+Kd 1 0 0
+d 0.5
+map_Kd -clamp on red.png
+`,
+	}
+
+	data := parseObjString(t, "mtllib colours.mtl\n" + objSquare + "usemtl red\nf 1 2 3\nusemtl none\nf 1 3 4\n", libraries)
+
+	red, found := data.Materials["red"]
+	if !found {
+		t.Fatalf("materials %v, expected red", data.Materials)
+	}
+	if red.Diffuse != (mgl32.Vec3{1, 0, 0}) || red.Opacity != 0.5 || red.DiffuseMap != "red.png" {
+		t.Errorf("red is %+v", red)
+	}
+	if red.Shininess != 8.0 {
+		t.Errorf("red has shininess %v, expected the default 8", red.Shininess)
+	}
+
+	// The vertices of the red triangle have its colour, the ones with an unknown material are white
+	if colour := data.Colours[0:4]; colour[0] != 1 || colour[1] != 0 || colour[2] != 0 || colour[3] != 0.5 {
+		t.Errorf("red vertex colour is %v", colour)
+	}
+	if colour := data.Colours[3 * 4:4 * 4]; colour[0] != 1 || colour[1] != 1 || colour[2] != 1 || colour[3] != 1 {
+		t.Errorf("vertex without a material colour is %v", colour)
+	}
+}
+
+func TestObjMaterialLibraryAfterUse(t *testing.T) {
+	libraries := map[string]string{"late.mtl": "newmtl blue\nKd 0 0 1\n"}
+
+	// The colours are set once every library is loaded
+	data := parseObjString(t, objSquare + "usemtl blue\nf 1 2 3\nmtllib late.mtl\n", libraries)
+
+	for vertex := uint32(0); vertex < 3; vertex++ {
+		if colour := data.Colours[vertex * 4:vertex * 4 + 3]; colour[0] != 0 || colour[1] != 0 || colour[2] != 1 {
+			t.Errorf("vertex %d colour is %v, expected blue", vertex, colour)
+		}
+	}
+}
+
+func TestObjErrors(t *testing.T) {
+	sources := map[string]string{
+		"index out of range": objSquare + "f 1 2 5\n",
+		"index zero": objSquare + "f 0 1 2\n",
+		"face with 2 vertices": objSquare + "f 1 2\n",
+		"usemtl without a name": "usemtl\n",
+		"invalid number": "v 0 x 0\n",
+		"missing material library": "mtllib missing.mtl\n",
+		"mtl statement before newmtl": "mtllib broken.mtl\n",
+	}
+	libraries := map[string]string{"broken.mtl": "Kd 1 1 1\n"}
+
+	for name, source := range sources {
+		_, err := ParseObj(strings.NewReader(source), func(name string) (io.ReadCloser, error) {
+			library, found := libraries[name]
+			if !found {
+				return nil, fmt.Errorf("no material library %q", name)
+			}
+			return io.NopCloser(strings.NewReader(library)), nil
+		})
+
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadObjMaterials(t *testing.T) {
+	previous := gpu.Assets()
+	defer gpu.SetAssets(previous)
+	gpu.SetAssets(fstest.MapFS{
+		"models/colours.mtl": &fstest.MapFile{Data: []byte("newmtl red\nKd 1 0 0\nKs 0.5 0.5 0.5\nmap_Kd red.png\nnewmtl blue\nKd 0 0 1\n")},
+		"models/red.obj": &fstest.MapFile{Data: []byte("mtllib colours.mtl\n" + objSquare + "usemtl red\ng one\nf 1 2 3\ng two\nf 1 3 4\n")},
+		"models/plain.obj": &fstest.MapFile{Data: []byte(objSquare + "f 1 2 3 4\n")},
+		"models/two.obj": &fstest.MapFile{Data: []byte("mtllib colours.mtl\n" + objSquare + "usemtl red\nf 1 2 3\nusemtl blue\nf 1 3 4\n")},
+		"models/partial.obj": &fstest.MapFile{Data: []byte("mtllib colours.mtl\n" + objSquare + "f 1 2 3\nusemtl red\nf 1 3 4\n")},
+	})
+
+	// Several groups with the same material are drawn with it, its texture is relative to the OBJ file
+	mesh, err := LoadObj("models/red.obj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if material := mesh.GetMaterial(); material.Name != "red" || material.Specular != (mgl32.Vec3{0.5, 0.5, 0.5}) || material.DiffuseMap != "models/red.png" {
+		t.Errorf("material is %+v, expected red", material)
+	}
+
+	// Without materials the mesh keeps the default one
+	if mesh, err = LoadObj("models/plain.obj"); err != nil || mesh.GetMaterial().Name != "default" {
+		t.Errorf("mesh without materials: %v, %v", mesh, err)
+	}
+
+	// The mesh can't be drawn with more than one material
+	for _, path := range []string{"models/two.obj", "models/partial.obj"} {
+		if _, err := LoadObj(path); err == nil {
+			t.Errorf("%s: expected an error, its faces use two materials", path)
+		}
+	}
+}
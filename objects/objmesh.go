@@ -0,0 +1,110 @@
+package objects
+
+import (
+	"../gpu"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Mesh loaded from an OBJ file, drawn with indexed triangles
+type ObjMesh struct {
+	bufferObject, normalsObject, coloursObject uint32
+	texCoordsObject                            uint32
+	elementBuffer                              uint32
+	vertexArray                                uint32 // Vertex array object with the attributes and the element buffer
+
+	DrawMode                                   DrawMode // Defines drawing mode of the mesh as points, lines or filled polygons
+
+	Data                                       *ObjData
+
+	Transform
+	Surface
+}
+
+func NewObjMesh(data *ObjData) *ObjMesh {
+	return &ObjMesh{
+		0, 0, 0, // bufferObject, normals, colours
+		0, // texCoords
+		0, // elementBuffer
+		0, // vertexArray
+		DRAW_POLYGONS, // drawmode
+		data, // data
+		NewTransform(), // transform
+		NewSurface(), // surface
+	}
+}
+
+func (mesh *ObjMesh) MakeVBO() {
+	// Deletes the buffers of a previous call, so calling it again doesn't leak them
+	mesh.Release()
+
+	// Create a vertex buffer object to store the vertices
+	mesh.bufferObject = gpu.GenBuffer("mesh positions")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.bufferObject)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Positions) * 4, gl.Ptr(mesh.Data.Positions), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// Create a vertex buffer object to store the vertex colours
+	mesh.coloursObject = gpu.GenBuffer("mesh colours")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.coloursObject)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Colours) * 4, gl.Ptr(mesh.Data.Colours), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// Create the normals buffer
+	mesh.normalsObject = gpu.GenBuffer("mesh normals")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.normalsObject)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Normals) * 4, gl.Ptr(mesh.Data.Normals), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// Create the texture coordinates buffer
+	mesh.texCoordsObject = gpu.GenBuffer("mesh texture coordinates")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.texCoordsObject)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.TexCoords) * 4, gl.Ptr(mesh.Data.TexCoords), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// Generate a buffer for the indices (uploaded through GL_ARRAY_BUFFER, the element buffer binding belongs to the vertex array)
+	mesh.elementBuffer = gpu.GenBuffer("mesh indices")
+	gl.BindBuffer(gl.ARRAY_BUFFER, mesh.elementBuffer)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh.Data.Indices) * 4, gl.Ptr(mesh.Data.Indices), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// Positions, colours, normals and texture coordinates are in attribute indices 0, 1, 2 and 3
+	mesh.vertexArray = gpu.NewVertexArray("mesh", gpu.VertexLayout{
+		Attributes: []gpu.VertexAttribute{
+			gpu.FloatAttribute(0, mesh.bufferObject, 3), // positions
+			gpu.FloatAttribute(1, mesh.coloursObject, 4), // colours
+			gpu.FloatAttribute(2, mesh.normalsObject, 3), // normals
+			gpu.FloatAttribute(3, mesh.texCoordsObject, 2), // texture coordinates
+		},
+		Elements: mesh.elementBuffer, // indices
+	})
+}
+
+func (mesh *ObjMesh) Draw() {
+	/* Bind the mesh vertex array, it has all the attributes and the indices */
+	gl.BindVertexArray(mesh.vertexArray)
+
+	if mesh.DrawMode == DRAW_LINES {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.LINE)
+	} else {
+		gl.PolygonMode(gl.FRONT_AND_BACK, gl.FILL)
+	}
+
+	if mesh.DrawMode == DRAW_POINTS {
+		gpu.DrawArrays(gl.POINTS, 0, int32(len(mesh.Data.Positions) / 3))
+	} else {
+		gpu.DrawElements(gl.TRIANGLES, int32(len(mesh.Data.Indices)), gl.UNSIGNED_INT, nil)
+	}
+
+	gl.BindVertexArray(0)
+}
+
+// Deletes the buffer objects and the vertex array of the mesh (MakeVBO creates them again)
+func (mesh *ObjMesh) Release() {
+	gpu.DeleteVertexArray(&mesh.vertexArray)
+	gpu.DeleteBuffer(&mesh.bufferObject)
+	gpu.DeleteBuffer(&mesh.coloursObject)
+	gpu.DeleteBuffer(&mesh.normalsObject)
+	gpu.DeleteBuffer(&mesh.texCoordsObject)
+	gpu.DeleteBuffer(&mesh.elementBuffer)
+}
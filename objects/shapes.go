@@ -0,0 +1,390 @@
+package objects
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Attributes of the procedural shapes, the colours are the x,y,z of the positions (like the sphere's)
+var shapeAttributes = []MeshAttribute{ATTRIBUTE_POSITION, ATTRIBUTE_COLOUR, ATTRIBUTE_NORMAL, ATTRIBUTE_TEXCOORD}
+
+// Mesh builder for the procedural shapes, it remembers the position of each vertex to drop degenerate triangles
+type shapeBuilder struct {
+	*MeshBuilder
+	positions []mgl32.Vec3
+}
+
+func newShapeBuilder() *shapeBuilder {
+	return &shapeBuilder{
+		NewMeshBuilder(shapeAttributes...), // mesh builder
+		nil, // positions
+	}
+}
+
+// Adds a vertex with its position, normal and texture coordinates, and returns its index
+func (shape *shapeBuilder) vertex(position, normal mgl32.Vec3, u, v float32) uint32 {
+	colour := []float32{position[0], position[1], position[2], 1.0}
+
+	// The values always match shapeAttributes, so AddVertex can't fail
+	index, _ := shape.AddVertex(position[:], colour, normal[:], []float32{u, v})
+	if int(index) == len(shape.positions) {
+		shape.positions = append(shape.positions, position)
+	}
+
+	return index
+}
+
+// Adds a triangle (counterclockwise is the front face), unless two of its corners are at the same position (like at a pole)
+func (shape *shapeBuilder) triangle(a, b, c uint32) {
+	if shape.positions[a] == shape.positions[b] || shape.positions[b] == shape.positions[c] || shape.positions[c] == shape.positions[a] {
+		return
+	}
+
+	shape.AddTriangle(a, b, c)
+}
+
+//
+// Grid
+// Adds a grid of (columns + 1) x (rows + 1) vertices and the two triangles of each cell.
+// The triangles face the side where the columns go right and the rows go up.
+//
+// @param columns (int) the number of cells along u
+// @param rows (int) the number of cells along v
+// @param vertex (func(column, row int) (mgl32.Vec3, mgl32.Vec3)) the position and the normal of a vertex of the grid
+//
+func (shape *shapeBuilder) grid(columns, rows int, vertex func(column, row int) (mgl32.Vec3, mgl32.Vec3)) {
+	indices := make([]uint32, (columns + 1) * (rows + 1))
+	for row := 0; row <= rows; row++ {
+		for column := 0; column <= columns; column++ {
+			position, normal := vertex(column, row)
+			indices[row * (columns + 1) + column] = shape.vertex(position, normal, float32(column) / float32(columns), float32(row) / float32(rows))
+		}
+	}
+
+	for row := 0; row < rows; row++ {
+		for column := 0; column < columns; column++ {
+			a := indices[row * (columns + 1) + column]
+			b := indices[row * (columns + 1) + column + 1]
+			c := indices[(row + 1) * (columns + 1) + column + 1]
+			d := indices[row * (columns + 1) + column + (columns + 1)]
+
+			shape.triangle(a, b, c)
+			shape.triangle(a, c, d)
+		}
+	}
+}
+
+//
+// Disc
+// Adds a flat disc facing up or down, with a vertex in the centre (the cap of a cylinder or a cone)
+//
+// @param y (float32) the height of the disc
+// @param radius (float32) the radius of the disc
+// @param segments (int) the number of triangles around the centre
+// @param up (bool) true if the disc faces +y, false if it faces -y
+//
+func (shape *shapeBuilder) disc(y, radius float32, segments int, up bool) {
+	normal := mgl32.Vec3{0, -1, 0}
+	if up {
+		normal = mgl32.Vec3{0, 1, 0}
+	}
+
+	centre := shape.vertex(mgl32.Vec3{0, y, 0}, normal, 0.5, 0.5)
+
+	ring := make([]uint32, segments + 1)
+	for i := 0; i <= segments; i++ {
+		direction := aroundY(i, segments)
+		ring[i] = shape.vertex(direction.Mul(radius).Add(mgl32.Vec3{0, y, 0}), normal, 0.5 + 0.5 * direction[0], 0.5 - 0.5 * direction[2])
+	}
+
+	for i := 0; i < segments; i++ {
+		if up {
+			shape.triangle(centre, ring[i], ring[i + 1])
+		} else {
+			shape.triangle(centre, ring[i + 1], ring[i])
+		}
+	}
+}
+
+// Returns the direction of step i of n around the y axis, counterclockwise seen from above (the last step is the first again)
+func aroundY(i, n int) mgl32.Vec3 {
+	angle := 2.0 * math.Pi * float64(i % n) / float64(n)
+	return mgl32.Vec3{float32(math.Cos(angle)), 0, float32(-math.Sin(angle))}
+}
+
+//
+// Make Cylinder
+// Makes a cylinder along the y axis, centred at the origin and closed by a disc at each end
+//
+// @param radius (float32) the radius of the cylinder
+// @param height (float32) the length of the cylinder
+// @param segments (int) the number of faces around the y axis (at least 3)
+// @param rings (int) the number of faces along the y axis (at least 1)
+//
+// @return data (*MeshData) the mesh, to draw with NewMesh
+// @return error (error) the error (if the sizes or the number of faces aren't valid)
+//
+func MakeCylinder(radius, height float32, segments, rings int) (*MeshData, error) {
+	if radius <= 0 || height <= 0 || segments < 3 || rings < 1 {
+		return nil, fmt.Errorf("invalid cylinder: radius %v, height %v, %d segments, %d rings", radius, height, segments, rings)
+	}
+
+	shape := newShapeBuilder()
+
+	shape.grid(segments, rings, func(column, row int) (mgl32.Vec3, mgl32.Vec3) {
+		direction := aroundY(column, segments)
+		y := -height / 2.0 + height * float32(row) / float32(rings)
+		return direction.Mul(radius).Add(mgl32.Vec3{0, y, 0}), direction
+	})
+
+	shape.disc(height / 2.0, radius, segments, true)
+	shape.disc(-height / 2.0, radius, segments, false)
+
+	return shape.Pack()
+}
+
+//
+// Make Cone
+// Makes a cone along the y axis, centred at the origin with the tip at the top and closed by a disc at the base
+//
+// @param radius (float32) the radius of the base
+// @param height (float32) the distance from the base to the tip
+// @param segments (int) the number of faces around the y axis (at least 3)
+// @param rings (int) the number of faces from the base to the tip (at least 1)
+//
+// @return data (*MeshData) the mesh, to draw with NewMesh
+// @return error (error) the error (if the sizes or the number of faces aren't valid)
+//
+func MakeCone(radius, height float32, segments, rings int) (*MeshData, error) {
+	if radius <= 0 || height <= 0 || segments < 3 || rings < 1 {
+		return nil, fmt.Errorf("invalid cone: radius %v, height %v, %d segments, %d rings", radius, height, segments, rings)
+	}
+
+	shape := newShapeBuilder()
+
+	// The normals lean up by the slope of the side, the tip has one vertex for each of them
+	shape.grid(segments, rings, func(column, row int) (mgl32.Vec3, mgl32.Vec3) {
+		direction := aroundY(column, segments)
+		fraction := float32(row) / float32(rings)
+		position := direction.Mul(radius * (1.0 - fraction)).Add(mgl32.Vec3{0, -height / 2.0 + height * fraction, 0})
+		normal := direction.Mul(height).Add(mgl32.Vec3{0, radius, 0}).Normalize()
+		return position, normal
+	})
+
+	shape.disc(-height / 2.0, radius, segments, false)
+
+	return shape.Pack()
+}
+
+//
+// Make Torus
+// Makes a torus (a ring) around the y axis, centred at the origin
+//
+// @param majorRadius (float32) the distance from the centre to the middle of the tube
+// @param minorRadius (float32) the radius of the tube (smaller than majorRadius)
+// @param segments (int) the number of faces around the y axis (at least 3)
+// @param sides (int) the number of faces around the tube (at least 3)
+//
+// @return data (*MeshData) the mesh, to draw with NewMesh
+// @return error (error) the error (if the sizes or the number of faces aren't valid)
+//
+func MakeTorus(majorRadius, minorRadius float32, segments, sides int) (*MeshData, error) {
+	if minorRadius <= 0 || majorRadius <= minorRadius || segments < 3 || sides < 3 {
+		return nil, fmt.Errorf("invalid torus: radii %v and %v, %d segments, %d sides", majorRadius, minorRadius, segments, sides)
+	}
+
+	shape := newShapeBuilder()
+
+	// Each row is a circle around the y axis, the rows go around the tube from the outside, over the top
+	shape.grid(segments, sides, func(column, row int) (mgl32.Vec3, mgl32.Vec3) {
+		direction := aroundY(column, segments)
+		angle := 2.0 * math.Pi * float64(row % sides) / float64(sides)
+
+		normal := direction.Mul(float32(math.Cos(angle))).Add(mgl32.Vec3{0, float32(math.Sin(angle)), 0})
+		return direction.Mul(majorRadius).Add(normal.Mul(minorRadius)), normal
+	})
+
+	return shape.Pack()
+}
+
+//
+// Make Plane
+// Makes a flat grid on the xz plane facing up (+y), centred at the origin
+//
+// @param width (float32) the size along x
+// @param depth (float32) the size along z
+// @param columns (int) the number of cells along x (at least 1)
+// @param rows (int) the number of cells along z (at least 1)
+//
+// @return data (*MeshData) the mesh, to draw with NewMesh
+// @return error (error) the error (if the sizes or the number of cells aren't valid)
+//
+func MakePlane(width, depth float32, columns, rows int) (*MeshData, error) {
+	if width <= 0 || depth <= 0 || columns < 1 || rows < 1 {
+		return nil, fmt.Errorf("invalid plane: %v x %v, %d columns, %d rows", width, depth, columns, rows)
+	}
+
+	shape := newShapeBuilder()
+
+	// The rows go from the front (+z) to the back, so v goes up the texture seen from above
+	shape.grid(columns, rows, func(column, row int) (mgl32.Vec3, mgl32.Vec3) {
+		x := -width / 2.0 + width * float32(column) / float32(columns)
+		z := depth / 2.0 - depth * float32(row) / float32(rows)
+		return mgl32.Vec3{x, 0, z}, mgl32.Vec3{0, 1, 0}
+	})
+
+	return shape.Pack()
+}
+
+//
+// Make Capsule
+// Makes a capsule along the y axis, centred at the origin: a cylinder with a half sphere at each end
+//
+// @param radius (float32) the radius of the cylinder and the half spheres
+// @param height (float32) the length of the cylinder (0 makes a sphere)
+// @param segments (int) the number of faces around the y axis (at least 3)
+// @param rings (int) the number of faces from the side to the tip of each half sphere (at least 1)
+//
+// @return data (*MeshData) the mesh, to draw with NewMesh
+// @return error (error) the error (if the sizes or the number of faces aren't valid)
+//
+func MakeCapsule(radius, height float32, segments, rings int) (*MeshData, error) {
+	if radius <= 0 || height < 0 || segments < 3 || rings < 1 {
+		return nil, fmt.Errorf("invalid capsule: radius %v, height %v, %d segments, %d rings", radius, height, segments, rings)
+	}
+
+	shape := newShapeBuilder()
+
+	// The rows go from the bottom tip to the top one, the bottom half sphere has rings + 1 of them and the top one too.
+	// The last row of the bottom and the first of the top are the ends of the cylinder.
+	shape.grid(segments, 2 * rings + 1, func(column, row int) (mgl32.Vec3, mgl32.Vec3) {
+		direction := aroundY(column, segments)
+
+		centre := float32(-height / 2.0)
+		latitude := -math.Pi / 2.0 + math.Pi / 2.0 * float64(row) / float64(rings)
+		if row > rings {
+			centre = height / 2.0
+			latitude = math.Pi / 2.0 * float64(row - rings - 1) / float64(rings)
+		}
+
+		normal := direction.Mul(float32(math.Cos(latitude))).Add(mgl32.Vec3{0, float32(math.Sin(latitude)), 0})
+		if row == 0 || row == 2 * rings + 1 {
+			// Exactly at the tips, cos(±90°) isn't exactly 0
+			normal = mgl32.Vec3{0, normal[1], 0}.Normalize()
+		}
+
+		return normal.Mul(radius).Add(mgl32.Vec3{0, centre, 0}), normal
+	})
+
+	return shape.Pack()
+}
+
+//
+// Make Icosphere
+// Makes a sphere centred at the origin by subdividing an icosahedron, its triangles are all about the same size
+// (unlike the UV sphere's, which get thin at the poles). Each subdivision splits every triangle in 4.
+// The texture coordinates are equirectangular like the sphere's, the vertices at the seam are duplicated.
+//
+// @param radius (float32) the radius of the sphere
+// @param subdivisions (int) the number of times the icosahedron is subdivided (0 to 7)
+//
+// @return data (*MeshData) the mesh with 20 * 4^subdivisions triangles, to draw with NewMesh
+// @return error (error) the error (if the radius or the subdivisions aren't valid)
+//
+func MakeIcosphere(radius float32, subdivisions int) (*MeshData, error) {
+	if radius <= 0 || subdivisions < 0 || subdivisions > 7 {
+		return nil, fmt.Errorf("invalid icosphere: radius %v, %d subdivisions", radius, subdivisions)
+	}
+
+	// The 12 vertices of an icosahedron are the corners of 3 orthogonal golden rectangles
+	phi := float32((1.0 + math.Sqrt(5.0)) / 2.0)
+	points := []mgl32.Vec3{
+		{-1, phi, 0}, {1, phi, 0}, {-1, -phi, 0}, {1, -phi, 0},
+		{0, -1, phi}, {0, 1, phi}, {0, -1, -phi}, {0, 1, -phi},
+		{phi, 0, -1}, {phi, 0, 1}, {-phi, 0, -1}, {-phi, 0, 1},
+	}
+	for i := range points {
+		points[i] = points[i].Normalize()
+	}
+
+	faces := [][3]int{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+
+	for level := 0; level < subdivisions; level++ {
+		// The point in the middle of each edge is shared by the two triangles of the edge
+		middles := make(map[[2]int]int)
+		middle := func(a, b int) int {
+			if a > b {
+				a, b = b, a
+			}
+			if index, found := middles[[2]int{a, b}]; found {
+				return index
+			}
+
+			points = append(points, points[a].Add(points[b]).Normalize())
+			middles[[2]int{a, b}] = len(points) - 1
+			return len(points) - 1
+		}
+
+		subdivided := make([][3]int, 0, len(faces) * 4)
+		for _, face := range faces {
+			ab, bc, ca := middle(face[0], face[1]), middle(face[1], face[2]), middle(face[2], face[0])
+			subdivided = append(subdivided, [3]int{face[0], ab, ca}, [3]int{face[1], bc, ab}, [3]int{face[2], ca, bc}, [3]int{ab, bc, ca})
+		}
+		faces = subdivided
+	}
+
+	shape := newShapeBuilder()
+
+	for _, face := range faces {
+		var u, v [3]float32
+		for i, point := range face {
+			u[i], v[i] = equirectangular(points[point])
+		}
+
+		// A triangle across the seam has corners near u = 0 and near u = 1, the ones near 0 are moved past 1
+		if maxOf(u) - minOf(u) > 0.5 {
+			for i := range u {
+				if u[i] < 0.5 {
+					u[i] += 1.0
+				}
+			}
+		}
+
+		// The poles don't have a longitude, they take the middle of the other two corners
+		for i, point := range face {
+			if points[point][0] == 0 && points[point][2] == 0 {
+				u[i] = (u[(i + 1) % 3] + u[(i + 2) % 3]) / 2.0
+			}
+		}
+
+		var corners [3]uint32
+		for i, point := range face {
+			corners[i] = shape.vertex(points[point].Mul(radius), points[point], u[i], v[i])
+		}
+		shape.triangle(corners[0], corners[1], corners[2])
+	}
+
+	return shape.Pack()
+}
+
+// Returns the equirectangular texture coordinates of a direction (u around the y axis, v from the bottom to the top)
+func equirectangular(direction mgl32.Vec3) (float32, float32) {
+	u := 0.5 + math.Atan2(float64(-direction[2]), float64(direction[0])) / (2.0 * math.Pi)
+	v := 0.5 + math.Asin(float64(mgl32.Clamp(direction[1], -1, 1))) / math.Pi
+	return float32(u), float32(v)
+}
+
+func minOf(values [3]float32) float32 {
+	return float32(math.Min(float64(values[0]), math.Min(float64(values[1]), float64(values[2]))))
+}
+
+func maxOf(values [3]float32) float32 {
+	return float32(math.Max(float64(values[0]), math.Max(float64(values[1]), float64(values[2]))))
+}
@@ -0,0 +1,257 @@
+package objects
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Returns the indices of a packed mesh, whatever their size
+func meshIndices(data *MeshData) []int {
+	indices := make([]int, data.IndexCount())
+	for i := range indices {
+		if data.IndexType == gl.UNSIGNED_SHORT {
+			indices[i] = int(data.Indices16[i])
+		} else {
+			indices[i] = int(data.Indices32[i])
+		}
+	}
+	return indices
+}
+
+func meshVec3(data *MeshData, vertex int, name string) mgl32.Vec3 {
+	values := data.Attribute(vertex, name)
+	return mgl32.Vec3{values[0], values[1], values[2]}
+}
+
+// Gives the vertices at the same position (like the ones at a seam) the same id
+func weldPositions(data *MeshData) []string {
+	ids := make([]string, data.VertexCount())
+	for i := range ids {
+		position := meshVec3(data, i, "position").Mul(1e4)
+		ids[i] = fmt.Sprint(math.Round(float64(position[0])) + 0, math.Round(float64(position[1])) + 0, math.Round(float64(position[2])) + 0)
+	}
+	return ids
+}
+
+// Counts the different positions of the vertices of a mesh
+func countPositions(data *MeshData) int {
+	unique := make(map[string]bool)
+	for _, id := range weldPositions(data) {
+		unique[id] = true
+	}
+	return len(unique)
+}
+
+// A mesh is closed if every edge is shared by two triangles that go along it in opposite directions
+func checkClosed(t *testing.T, name string, data *MeshData) {
+	ids := weldPositions(data)
+	indices := meshIndices(data)
+
+	edges := make(map[[2]string]int)
+	for i := 0; i < len(indices); i += 3 {
+		for corner := 0; corner < 3; corner++ {
+			from, to := ids[indices[i + corner]], ids[indices[i + (corner + 1) % 3]]
+			edges[[2]string{from, to}]++
+		}
+	}
+
+	for edge, count := range edges {
+		if count != 1 || edges[[2]string{edge[1], edge[0]}] != 1 {
+			t.Errorf("%s: edge %v is used %d times and its opposite %d times, expected once each", name, edge, count, edges[[2]string{edge[1], edge[0]}])
+			return
+		}
+	}
+}
+
+// Checks that the normals have length 1 and that the triangles face the side of their normals (counterclockwise)
+func checkNormals(t *testing.T, name string, data *MeshData) {
+	for i := 0; i < data.VertexCount(); i++ {
+		if length := meshVec3(data, i, "normal").Len(); math.Abs(float64(length) - 1) > 1e-4 {
+			t.Errorf("%s: normal of vertex %d has length %v", name, i, length)
+			return
+		}
+	}
+
+	indices := meshIndices(data)
+	for i := 0; i < len(indices); i += 3 {
+		a, b, c := meshVec3(data, indices[i], "position"), meshVec3(data, indices[i + 1], "position"), meshVec3(data, indices[i + 2], "position")
+		face := b.Sub(a).Cross(c.Sub(a))
+
+		normals := meshVec3(data, indices[i], "normal").Add(meshVec3(data, indices[i + 1], "normal")).Add(meshVec3(data, indices[i + 2], "normal"))
+		if face.Dot(normals) <= 0 {
+			t.Errorf("%s: triangle %d faces away from its normals", name, i / 3)
+			return
+		}
+	}
+}
+
+// Checks that the normals point away from a centre, given for each vertex
+func checkOutwards(t *testing.T, name string, data *MeshData, centre func(position mgl32.Vec3) mgl32.Vec3) {
+	for i := 0; i < data.VertexCount(); i++ {
+		position := meshVec3(data, i, "position")
+		if meshVec3(data, i, "normal").Dot(position.Sub(centre(position))) <= 0 {
+			t.Errorf("%s: normal of vertex %d at %v points inwards", name, i, position)
+			return
+		}
+	}
+}
+
+func origin(position mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.Vec3{0, 0, 0}
+}
+
+func TestShapeCounts(t *testing.T) {
+	cylinder, _ := MakeCylinder(0.5, 2, 12, 3)
+	cone, _ := MakeCone(0.5, 1, 10, 4)
+	torus, _ := MakeTorus(1, 0.25, 16, 8)
+	plane, _ := MakePlane(2, 1, 4, 3)
+	capsule, _ := MakeCapsule(0.5, 1, 12, 4)
+	icosphere, _ := MakeIcosphere(1, 2)
+
+	shapes := []struct {
+		name      string
+		data      *MeshData
+		positions int
+		triangles int
+	}{
+		{"cylinder", cylinder, 12 * 4 + 2, 2 * 12 * 3 + 2 * 12},
+		{"cone", cone, 10 * 4 + 2, 2 * 10 * 4},
+		{"torus", torus, 16 * 8, 2 * 16 * 8},
+		{"plane", plane, 5 * 4, 2 * 4 * 3},
+		{"capsule", capsule, 2 + 2 * 4 * 12, 4 * 12 * 4},
+		{"icosphere", icosphere, 10 * 16 + 2, 20 * 16},
+	}
+
+	for _, shape := range shapes {
+		if shape.data == nil {
+			t.Fatalf("%s wasn't made", shape.name)
+		}
+		if count := countPositions(shape.data); count != shape.positions {
+			t.Errorf("%s: %d positions, expected %d", shape.name, count, shape.positions)
+		}
+		if count := shape.data.IndexCount() / 3; count != shape.triangles {
+			t.Errorf("%s: %d triangles, expected %d", shape.name, count, shape.triangles)
+		}
+	}
+
+	// The plane has no duplicated vertices, the seams of the others add a column of vertices
+	if count := plane.VertexCount(); count != 5 * 4 {
+		t.Errorf("plane: %d vertices, expected %d", count, 5 * 4)
+	}
+	if count := cylinder.VertexCount(); count != 13 * 4 + 2 * (1 + 12) {
+		t.Errorf("cylinder: %d vertices, expected %d", count, 13 * 4 + 2 * (1 + 12))
+	}
+}
+
+func TestShapesAreClosed(t *testing.T) {
+	makers := map[string]func() (*MeshData, error){
+		"cylinder": func() (*MeshData, error) { return MakeCylinder(1, 1, 8, 2) },
+		"cone": func() (*MeshData, error) { return MakeCone(1, 2, 7, 3) },
+		"torus": func() (*MeshData, error) { return MakeTorus(1, 0.3, 12, 6) },
+		"capsule": func() (*MeshData, error) { return MakeCapsule(0.5, 1.5, 9, 3) },
+		"capsule without a cylinder": func() (*MeshData, error) { return MakeCapsule(0.5, 0, 9, 3) },
+		"icosahedron": func() (*MeshData, error) { return MakeIcosphere(1, 0) },
+		"icosphere": func() (*MeshData, error) { return MakeIcosphere(2, 3) },
+	}
+
+	for name, maker := range makers {
+		data, err := maker()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		checkClosed(t, name, data)
+		checkNormals(t, name, data)
+	}
+
+	// The plane is open, but it still has to face up
+	plane, _ := MakePlane(1, 1, 3, 3)
+	checkNormals(t, "plane", plane)
+	if normal := meshVec3(plane, 0, "normal"); normal != (mgl32.Vec3{0, 1, 0}) {
+		t.Errorf("plane: normal %v, expected +y", normal)
+	}
+}
+
+func TestShapeNormalsPointOutwards(t *testing.T) {
+	cylinder, _ := MakeCylinder(1, 3, 8, 2)
+	checkOutwards(t, "cylinder", cylinder, origin)
+
+	cone, _ := MakeCone(1, 2, 8, 2)
+	checkOutwards(t, "cone", cone, origin)
+
+	capsule, _ := MakeCapsule(0.5, 2, 8, 3)
+	checkOutwards(t, "capsule", capsule, origin)
+
+	icosphere, _ := MakeIcosphere(1, 2)
+	checkOutwards(t, "icosphere", icosphere, origin)
+
+	// The torus normals point away from the middle of the tube
+	torus, _ := MakeTorus(2, 0.5, 12, 8)
+	checkOutwards(t, "torus", torus, func(position mgl32.Vec3) mgl32.Vec3 {
+		return mgl32.Vec3{position[0], 0, position[2]}.Normalize().Mul(2)
+	})
+
+	// On a sphere the normal is the direction of the position
+	for i := 0; i < icosphere.VertexCount(); i++ {
+		if !meshVec3(icosphere, i, "normal").ApproxEqualThreshold(meshVec3(icosphere, i, "position"), 1e-5) {
+			t.Fatalf("icosphere: normal %v at %v", meshVec3(icosphere, i, "normal"), meshVec3(icosphere, i, "position"))
+		}
+	}
+}
+
+func TestShapeTextureCoordinates(t *testing.T) {
+	shapes := map[string]func() (*MeshData, error){
+		"cylinder": func() (*MeshData, error) { return MakeCylinder(1, 1, 8, 2) },
+		"torus": func() (*MeshData, error) { return MakeTorus(1, 0.3, 12, 6) },
+		"plane": func() (*MeshData, error) { return MakePlane(1, 1, 3, 3) },
+		"icosphere": func() (*MeshData, error) { return MakeIcosphere(1, 2) },
+	}
+
+	for name, maker := range shapes {
+		data, _ := maker()
+
+		// The icosphere's seam vertices are moved past u = 1 so the triangles don't wrap back
+		for i := 0; i < data.VertexCount(); i++ {
+			texcoord := data.Attribute(i, "texcoord")
+			if texcoord[0] < 0 || texcoord[0] > 1.5 || texcoord[1] < 0 || texcoord[1] > 1 {
+				t.Errorf("%s: texture coordinates %v of vertex %d", name, texcoord, i)
+				break
+			}
+		}
+
+		// No triangle spans more than half of the texture
+		indices := meshIndices(data)
+		for i := 0; i < len(indices); i += 3 {
+			a, b, c := data.Attribute(indices[i], "texcoord")[0], data.Attribute(indices[i + 1], "texcoord")[0], data.Attribute(indices[i + 2], "texcoord")[0]
+			if maxOf([3]float32{a, b, c}) - minOf([3]float32{a, b, c}) > 0.5 {
+				t.Errorf("%s: triangle %d wraps around the texture (u %v, %v, %v)", name, i / 3, a, b, c)
+				break
+			}
+		}
+	}
+}
+
+func TestShapeErrors(t *testing.T) {
+	if _, err := MakeCylinder(1, 1, 2, 1); err == nil {
+		t.Error("expected an error for a cylinder with 2 segments")
+	}
+	if _, err := MakeCone(0, 1, 8, 1); err == nil {
+		t.Error("expected an error for a cone without a radius")
+	}
+	if _, err := MakeTorus(0.5, 1, 8, 8); err == nil {
+		t.Error("expected an error for a torus with a tube wider than the ring")
+	}
+	if _, err := MakePlane(1, 1, 0, 1); err == nil {
+		t.Error("expected an error for a plane without columns")
+	}
+	if _, err := MakeCapsule(1, -1, 8, 2); err == nil {
+		t.Error("expected an error for a capsule with a negative height")
+	}
+	if _, err := MakeIcosphere(1, 8); err == nil {
+		t.Error("expected an error for an icosphere with 8 subdivisions")
+	}
+}
@@ -3,6 +3,8 @@ package objects
 import (
 	"math"
 
+	"../gpu"
+
 	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 )
@@ -12,36 +14,89 @@ const DEG_TO_RADIANS = 3.141592 / 180.0
 // Define buffer object indices
 type Sphere struct {
 	sphereBufferObject, sphereNormals, sphereColours uint32
+	sphereTexCoords                                  uint32
 	elementBuffer                                    uint32
+	vertexArray                                      uint32 // Vertex array object with the attributes and the element buffer
 
 	DrawMode                                         DrawMode // Defines drawing mode of sphere as points, lines or filled polygons
 	numLats, numLongs                                uint32      //Define the resolution of the sphere object
 
 	numSphereVertices                                uint32
 
-	Model                                            mgl32.Mat4
+	Transform
+	Surface
 }
 
 func NewSphere(numLats, numLongs uint32) *Sphere {
 	return &Sphere{
 		0, 0, 0, // sphereBufferObject, sphereNormals, sphereColours
+		0, // sphereTexCoords
 		0, // elementBuffer
+		0, // vertexArray
 		DRAW_POLYGONS, // drawmode
 		numLats, numLongs, // numLats, numLongs
 		0, // numSphereVertices
-		mgl32.Ident4(), // model
+		NewTransform(), // transform
+		NewSurface(), // surface
 	}
 }
 
+// Smallest resolution MakeVBO can make a sphere with (a pole fan needs 3 longitudes, a strip needs 3 latitudes)
+const (
+	SPHERE_MIN_LATS  uint32 = 3
+	SPHERE_MIN_LONGS uint32 = 3
+)
+
+// Returns the number of latitudes and longitudes of the sphere
+func (sphere *Sphere) Resolution() (uint32, uint32) {
+	return sphere.numLats, sphere.numLongs
+}
+
+//
+// Set Resolution
+// Changes the number of latitudes and longitudes of the sphere, remaking its buffers if they were already made
+//
+// @param numLats (uint32) the number of latitudes (at least SPHERE_MIN_LATS)
+// @param numLongs (uint32) the number of longitudes (at least SPHERE_MIN_LONGS)
+//
+// @return changed (bool) true if the resolution is different from the previous one
+//
+func (sphere *Sphere) SetResolution(numLats, numLongs uint32) bool {
+	if numLats < SPHERE_MIN_LATS {
+		numLats = SPHERE_MIN_LATS
+	}
+	if numLongs < SPHERE_MIN_LONGS {
+		numLongs = SPHERE_MIN_LONGS
+	}
+
+	if numLats == sphere.numLats && numLongs == sphere.numLongs {
+		return false
+	}
+
+	sphere.numLats, sphere.numLongs = numLats, numLongs
+
+	// MakeVBO releases the old buffers first, the sphere is drawn with the new ones from the next draw
+	if sphere.vertexArray != 0 {
+		sphere.MakeVBO()
+	}
+
+	return true
+}
+
 // Make a sphere from two triangle fans (one at each pole) and triangle strips along latitudes
 // This version uses indexed vertex buffers for both the fans at the poles and the latitude strips
-func (sphere *Sphere) MakeSphereVBO() {
+// Each latitude has an extra vertex at the seam (same position as the first, with u = 1) so the texture doesn't wrap back
+func (sphere *Sphere) MakeVBO() {
+	// Deletes the buffers of a previous call, so the sphere can be made again (at another resolution) without leaking them
+	sphere.Release()
+
 	var i uint32
+	var rowLength uint32 = sphere.numLongs + 1 // Vertices in each latitude, including the seam
 
 	// Calculate the number of vertices required in sphere
-	sphere.numSphereVertices = 2 + ((sphere.numLats - 1) * sphere.numLongs)
+	sphere.numSphereVertices = 2 + ((sphere.numLats - 1) * rowLength)
 	pColours := make([]float32, (sphere.numSphereVertices * 4))
-	pVertices, pNormals := sphere.MakeUnitSphere()
+	pVertices, pNormals, pTexCoords := sphere.MakeUnitSphere()
 
 	// Define colours as the x,y,z components of the sphere vertices
 	for i = 0; i < sphere.numSphereVertices; i++ {
@@ -52,23 +107,29 @@ func (sphere *Sphere) MakeSphereVBO() {
 	}
 
 	/* Generate the vertex buffer object */
-	gl.GenBuffers(1, &sphere.sphereBufferObject)
+	sphere.sphereBufferObject = gpu.GenBuffer("sphere positions")
 	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereBufferObject)
 	gl.BufferData(gl.ARRAY_BUFFER, int(4 * sphere.numSphereVertices * 3), gl.Ptr(pVertices), gl.STATIC_DRAW)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 
 	/* Store the normals in a buffer object */
-	gl.GenBuffers(1, &sphere.sphereNormals)
+	sphere.sphereNormals = gpu.GenBuffer("sphere normals")
 	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereNormals)
 	gl.BufferData(gl.ARRAY_BUFFER, int(4 * sphere.numSphereVertices * 3), gl.Ptr(pNormals), gl.STATIC_DRAW)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 
 	/* Store the colours in a buffer object */
-	gl.GenBuffers(1, &sphere.sphereColours)
+	sphere.sphereColours = gpu.GenBuffer("sphere colours")
 	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereColours)
 	gl.BufferData(gl.ARRAY_BUFFER, int(4 * sphere.numSphereVertices * 4), gl.Ptr(pColours), gl.STATIC_DRAW)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
 
+	/* Store the texture coordinates in a buffer object */
+	sphere.sphereTexCoords = gpu.GenBuffer("sphere texture coordinates")
+	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereTexCoords)
+	gl.BufferData(gl.ARRAY_BUFFER, int(4 * sphere.numSphereVertices * 2), gl.Ptr(pTexCoords), gl.STATIC_DRAW)
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
 	/* Calculate the number of indices in our index array and allocate memory for it */
 	numIndices := ((sphere.numLongs * 2) + 2) * (sphere.numLats - 1) + ((sphere.numLongs + 2) * 2)
 	pIndices := make([]uint32, numIndices)
@@ -76,73 +137,81 @@ func (sphere *Sphere) MakeSphereVBO() {
 	// fill "indices" to define triangle strips
 	var index int = 0 // Current index
 
-	// Define indices for the first triangle fan for one pole
-	for i = 0; i < sphere.numLongs + 1; i++ {
+	// Define indices for the first triangle fan for one pole (the seam vertex joins the last triangle)
+	for i = 0; i < rowLength + 1; i++ {
 		pIndices[index] = i
 		index++
 	}
 
-	pIndices[index] = 1    // Join last triangle in the triangle fan
-	index++
-
 	var j uint32
 	var start uint32 = 1        // Start index for each latitude row
 	for j = 0; j < sphere.numLats - 2; j++ {
-		for i = 0; i < sphere.numLongs; i++ {
+		// The seam vertices close the triangle strip loop
+		for i = 0; i < rowLength; i++ {
 			pIndices[index] = start + i
 			index++
 
-			pIndices[index] = start + i + sphere.numLongs
+			pIndices[index] = start + i + rowLength
 			index++
 		}
 
-		// close the triangle strip loop by going back to the first vertex in the loop
-		pIndices[index] = start
-		index++
-
-		// close the triangle strip loop by going back to the first vertex in the loop
-		pIndices[index] = start + sphere.numLongs
-		index++
-
-		start += sphere.numLongs
+		start += rowLength
 	}
 
-	// Define indices for the last triangle fan for the south pole region
-	for i = sphere.numSphereVertices - 1; i > sphere.numSphereVertices - sphere.numLongs - 2; i-- {
+	// Define indices for the last triangle fan for the south pole region (the seam vertex ties up the last triangle)
+	for i = sphere.numSphereVertices - 1; i > sphere.numSphereVertices - rowLength - 2; i-- {
 		pIndices[index] = i
 		index++
 	}
-	pIndices[index] = sphere.numSphereVertices - 2 // Tie up last triangle in fan
-	index++
 
-	// Generate a buffer for the indices
-	gl.GenBuffers(1, &sphere.elementBuffer)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, sphere.elementBuffer)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, int(numIndices * 4), gl.Ptr(pIndices), gl.STATIC_DRAW)
+	// Generate a buffer for the indices (uploaded through GL_ARRAY_BUFFER, the element buffer binding belongs to the vertex array)
+	sphere.elementBuffer = gpu.GenBuffer("sphere indices")
+	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.elementBuffer)
+	gl.BufferData(gl.ARRAY_BUFFER, int(numIndices * 4), gl.Ptr(pIndices), gl.STATIC_DRAW)
 	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// Positions, colours, normals and texture coordinates are in attribute indices 0, 1, 2 and 3
+	sphere.vertexArray = gpu.NewVertexArray("sphere", gpu.VertexLayout{
+		Attributes: []gpu.VertexAttribute{
+			gpu.FloatAttribute(0, sphere.sphereBufferObject, 3), // positions
+			gpu.FloatAttribute(1, sphere.sphereColours, 4), // colours
+			gpu.FloatAttribute(2, sphere.sphereNormals, 3), // normals
+			gpu.FloatAttribute(3, sphere.sphereTexCoords, 2), // texture coordinates
+		},
+		Elements: sphere.elementBuffer, // indices
+	})
 }
 
-// Define the vertex positions for a sphere. The array of vertices must have previosuly been created.
-func (sphere *Sphere) MakeUnitSphere() ([]float32, []float32) {
+// Define the vertex positions, normals and texture coordinates for a unit sphere. numSphereVertices must have been calculated previously.
+// The texture coordinates are equirectangular: u goes around the longitudes and v from the south (0) to the north pole (1)
+func (sphere *Sphere) MakeUnitSphere() ([]float32, []float32, []float32) {
 	var vnum int32 = 0
 	var x, y, z, lat_radians, lon_radians float32
 	var lat, lon float32
+	var row, column uint32
 
 	pVertices := make([]float32, (sphere.numSphereVertices * 3))
+	pNormals := make([]float32, (sphere.numSphereVertices * 3))
+	pTexCoords := make([]float32, (sphere.numSphereVertices * 2))
 
 	// Define north pole
 	pVertices[0] = 0
 	pVertices[1] = 0
 	pVertices[2] = 1.0
+	pNormals[2] = 1.0
+	pTexCoords[0] = 0.5
+	pTexCoords[1] = 1.0
 	vnum++
 
 	latStep := 180.0 / float32(sphere.numLats)
 	longStep := 360.0 / float32(sphere.numLongs)
 
-	/* Define vertices along latitude lines */
-	for lat = 90.0 - latStep; lat > -90.0; lat -= latStep {
+	/* Define vertices along latitude lines, the last one of each latitude is at the seam */
+	for row = 1; row < sphere.numLats; row++ {
+		lat = 90.0 - float32(row) * latStep
 		lat_radians = lat * DEG_TO_RADIANS
-		for lon = -180.0; lon < 180.0; lon += longStep {
+		for column = 0; column <= sphere.numLongs; column++ {
+			lon = -180.0 + float32(column % sphere.numLongs) * longStep
 			lon_radians = lon * DEG_TO_RADIANS
 
 			x = float32(math.Cos(float64(lat_radians)) * math.Cos(float64(lon_radians)))
@@ -153,6 +222,16 @@ func (sphere *Sphere) MakeUnitSphere() ([]float32, []float32) {
 			pVertices[vnum * 3] = x
 			pVertices[vnum * 3 + 1] = y
 			pVertices[vnum * 3 + 2] = z
+
+			/* The normal points away from the centre, in the direction of the vertex */
+			normal := mgl32.Vec3{x, y, z}.Normalize()
+			pNormals[vnum * 3] = normal[0]
+			pNormals[vnum * 3 + 1] = normal[1]
+			pNormals[vnum * 3 + 2] = normal[2]
+
+			/* The seam vertex has u = 1, so the last column of the texture isn't squeezed back to u = 0 */
+			pTexCoords[vnum * 2] = float32(column) / float32(sphere.numLongs)
+			pTexCoords[vnum * 2 + 1] = 1.0 - float32(row) / float32(sphere.numLats)
 			vnum++
 		}
 	}
@@ -161,26 +240,17 @@ func (sphere *Sphere) MakeUnitSphere() ([]float32, []float32) {
 	pVertices[vnum * 3] = 0
 	pVertices[vnum * 3 + 1] = 0
 	pVertices[vnum * 3 + 2] = -1.0
+	pNormals[vnum * 3 + 2] = -1.0
+	pTexCoords[vnum * 2] = 0.5
+	pTexCoords[vnum * 2 + 1] = 0.0
 
-	return pVertices, pVertices
+	return pVertices, pNormals, pTexCoords
 }
 
 // Draws the sphere form the previously defined vertex and index buffers
-func (sphere *Sphere) DrawSphere() {
-	/* Draw the vertices as GL_POINTS */
-	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereBufferObject)
-	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 0, nil)
-	gl.EnableVertexAttribArray(0)
-
-	/* Bind the sphere colours */
-	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereColours)
-	gl.VertexAttribPointer(1, 4, gl.FLOAT, false, 0, nil)
-	gl.EnableVertexAttribArray(1)
-
-	/* Bind the sphere normals */
-	gl.BindBuffer(gl.ARRAY_BUFFER, sphere.sphereNormals)
-	gl.VertexAttribPointer(2, 3, gl.FLOAT, false, 0, nil)
-	gl.EnableVertexAttribArray(2)
+func (sphere *Sphere) Draw() {
+	/* Bind the sphere vertex array, it has all the attributes and the indexed vertex buffer */
+	gl.BindVertexArray(sphere.vertexArray)
 
 	gl.PointSize(3.0)
 
@@ -192,13 +262,10 @@ func (sphere *Sphere) DrawSphere() {
 	}
 
 	if sphere.DrawMode == DRAW_POINTS {
-		gl.DrawArrays(gl.POINTS, 0, int32(sphere.numSphereVertices))
+		gpu.DrawArrays(gl.POINTS, 0, int32(sphere.numSphereVertices))
 	} else {
-		/* Bind the indexed vertex buffer */
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, sphere.elementBuffer)
-
 		/* Draw the north pole regions as a triangle  */
-		gl.DrawElements(gl.TRIANGLE_FAN, int32(sphere.numLongs + 2), gl.UNSIGNED_INT, nil)
+		gpu.DrawElements(gl.TRIANGLE_FAN, int32(sphere.numLongs + 2), gl.UNSIGNED_INT, nil)
 
 		/* Calculate offsets into the indexed array. Note that we multiply offsets by 4
 		   because it is a memory offset the indices are type GLuint which is 4-bytes */
@@ -210,26 +277,22 @@ func (sphere *Sphere) DrawSphere() {
 
 		/* Draw the triangle strips of latitudes */
 		for i = 0; i < sphere.numLats - 2; i++ {
-			gl.DrawElements(gl.TRIANGLE_STRIP, int32(sphere.numLongs * 2 + 2), gl.UNSIGNED_INT, gl.PtrOffset(lat_offset_current))
+			gpu.DrawElements(gl.TRIANGLE_STRIP, int32(sphere.numLongs * 2 + 2), gl.UNSIGNED_INT, gl.PtrOffset(lat_offset_current))
 			lat_offset_current += (lat_offset_jump * 4)
 		}
 		/* Draw the south pole as a triangle fan */
-		gl.DrawElements(gl.TRIANGLE_FAN, int32(sphere.numLongs + 2), gl.UNSIGNED_INT, gl.PtrOffset(lat_offset_current))
+		gpu.DrawElements(gl.TRIANGLE_FAN, int32(sphere.numLongs + 2), gl.UNSIGNED_INT, gl.PtrOffset(lat_offset_current))
 	}
-}
-
-func (sphere *Sphere) ResetModel() {
-	sphere.Model = mgl32.Ident4()
-}
-
-func (sphere *Sphere) Translate(Tx, Ty, Tz float32) {
-	sphere.Model = sphere.Model.Mul4(mgl32.Translate3D(Tx, Ty, Tz))
-}
 
-func (sphere *Sphere) Scale(scaleX, scaleY, scaleZ float32) {
-	sphere.Model = sphere.Model.Mul4(mgl32.Scale3D(scaleX, scaleY, scaleZ))
+	gl.BindVertexArray(0)
 }
 
-func (sphere *Sphere) Rotate(angle float32, axis mgl32.Vec3) {
-	sphere.Model = sphere.Model.Mul4(mgl32.HomogRotate3D(angle, axis))
+// Deletes the buffer objects and the vertex array of the sphere (MakeVBO creates them again)
+func (sphere *Sphere) Release() {
+	gpu.DeleteVertexArray(&sphere.vertexArray)
+	gpu.DeleteBuffer(&sphere.sphereBufferObject)
+	gpu.DeleteBuffer(&sphere.sphereNormals)
+	gpu.DeleteBuffer(&sphere.sphereColours)
+	gpu.DeleteBuffer(&sphere.sphereTexCoords)
+	gpu.DeleteBuffer(&sphere.elementBuffer)
 }
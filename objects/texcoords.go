@@ -0,0 +1,85 @@
+package objects
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Texture axes of each face of a box, seen from outside: the position axis (and direction) along u and along v
+var boxFaceAxes = map[[2]int][2][2]int{
+	{0, 1}:  {{2, -1}, {1, 1}}, // +x: u along -z, v along +y
+	{0, -1}: {{2, 1}, {1, 1}},  // -x: u along +z, v along +y
+	{1, 1}:  {{0, 1}, {2, -1}}, // +y: u along +x, v along -z
+	{1, -1}: {{0, 1}, {2, 1}},  // -y: u along +x, v along +z
+	{2, 1}:  {{0, 1}, {1, 1}},  // +z: u along +x, v along +y
+	{2, -1}: {{0, -1}, {1, 1}}, // -z: u along -x, v along +y
+}
+
+// Generates per-face texture coordinates (u, v per vertex) for a box drawn as a triangle list,
+// so every face shows the whole texture the right way up when seen from outside.
+// The face of each triangle is the side of the box its centre is on, so the winding order doesn't matter.
+func BoxTexCoords(positions []float32) []float32 {
+	texCoords := make([]float32, len(positions) / 3 * 2)
+	if len(positions) < 9 {
+		return texCoords
+	}
+
+	// Bounds of the box
+	min := mgl32.Vec3{positions[0], positions[1], positions[2]}
+	max := min
+	for i := 0; i + 2 < len(positions); i += 3 {
+		for axis := 0; axis < 3; axis++ {
+			min[axis] = float32(math.Min(float64(min[axis]), float64(positions[i + axis])))
+			max[axis] = float32(math.Max(float64(max[axis]), float64(positions[i + axis])))
+		}
+	}
+	centre := min.Add(max).Mul(0.5)
+	size := max.Sub(min)
+
+	for triangle := 0; triangle + 8 < len(positions); triangle += 9 {
+		// Centre of the triangle relative to the centre of the box, scaled so the box is a unit cube
+		var triangleCentre mgl32.Vec3
+		for axis := 0; axis < 3; axis++ {
+			sum := positions[triangle + axis] + positions[triangle + 3 + axis] + positions[triangle + 6 + axis]
+			triangleCentre[axis] = (sum / 3.0 - centre[axis]) / nonZero(size[axis])
+		}
+
+		// The face is on the axis the triangle is furthest along
+		faceAxis := 0
+		for axis := 1; axis < 3; axis++ {
+			if abs(triangleCentre[axis]) > abs(triangleCentre[faceAxis]) {
+				faceAxis = axis
+			}
+		}
+		direction := 1
+		if triangleCentre[faceAxis] < 0 {
+			direction = -1
+		}
+		axes := boxFaceAxes[[2]int{faceAxis, direction}]
+
+		for vertex := triangle; vertex < triangle + 9; vertex += 3 {
+			for coordinate, axis := range axes {
+				relative := (positions[vertex + axis[0]] - centre[axis[0]]) / nonZero(size[axis[0]])
+				texCoords[vertex / 3 * 2 + coordinate] = 0.5 + float32(axis[1]) * relative
+			}
+		}
+	}
+
+	return texCoords
+}
+
+func abs(value float32) float32 {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// Avoids dividing by zero for flat boxes
+func nonZero(value float32) float32 {
+	if value == 0 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,135 @@
+package scene
+
+import (
+	"../objects"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// A node of the scene graph. Its transform is relative to its parent, so moving a node moves all its children.
+type Node struct {
+	Name     string
+
+	Position mgl32.Vec3 // Translation relative to the parent
+	Rotation mgl32.Vec3 // Rotation in radians around the x, y and z axis (applied in that order)
+	Scale    mgl32.Vec3 // Scale along each axis
+	Spin     mgl32.Vec3 // Rotation speed of the node (radians per second around the x, y and z axis)
+
+	Mesh     objects.Drawable // Mesh drawn at the position of the node (can be nil for grouping nodes)
+
+	Children []*Node
+	parent   *Node
+}
+
+func NewNode(name string, mesh objects.Drawable) *Node {
+	return &Node{
+		name, // name
+		mgl32.Vec3{0, 0, 0}, // position
+		mgl32.Vec3{0, 0, 0}, // rotation
+		mgl32.Vec3{1, 1, 1}, // scale
+		mgl32.Vec3{0, 0, 0}, // spin
+		mesh, // mesh
+		nil, nil, // children, parent
+	}
+}
+
+// Adds a child to the node (removing it from its previous parent) and returns the child.
+// A node can't be added to itself or to one of its descendants (that would make a loop), then nothing changes and it returns nil
+func (node *Node) AddChild(child *Node) *Node {
+	for ancestor := node; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor == child {
+			return nil
+		}
+	}
+
+	if child.parent != nil {
+		child.parent.RemoveChild(child)
+	}
+
+	child.parent = node
+	node.Children = append(node.Children, child)
+	return child
+}
+
+// Removes a direct child of the node, does nothing if it isn't a child of the node
+func (node *Node) RemoveChild(child *Node) {
+	for i, current := range node.Children {
+		if current == child {
+			node.Children = append(node.Children[:i], node.Children[i + 1:]...)
+			child.parent = nil
+			return
+		}
+	}
+}
+
+func (node *Node) GetParent() *Node {
+	return node.parent
+}
+
+// Finds the first node with the given name in this branch of the graph (nil if there is none)
+func (node *Node) Find(name string) *Node {
+	if node.Name == name {
+		return node
+	}
+
+	for _, child := range node.Children {
+		if found := child.Find(name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// Returns the transform of the node relative to its parent (translation * rotation * scale)
+func (node *Node) LocalMatrix() mgl32.Mat4 {
+	return mgl32.Translate3D(node.Position[0], node.Position[1], node.Position[2]).
+		Mul4(mgl32.HomogRotate3DX(node.Rotation[0])).
+		Mul4(mgl32.HomogRotate3DY(node.Rotation[1])).
+		Mul4(mgl32.HomogRotate3DZ(node.Rotation[2])).
+		Mul4(mgl32.Scale3D(node.Scale[0], node.Scale[1], node.Scale[2]))
+}
+
+// Returns the transform of the node in world space, combining the local transforms of all its ancestors
+func (node *Node) WorldMatrix() mgl32.Mat4 {
+	if node.parent == nil {
+		return node.LocalMatrix()
+	}
+
+	return node.parent.WorldMatrix().Mul4(node.LocalMatrix())
+}
+
+// Applies the spin of this node and all its children for the given number of seconds
+func (node *Node) Update(dt float32) {
+	node.Rotation = node.Rotation.Add(node.Spin.Mul(dt))
+
+	for _, child := range node.Children {
+		child.Update(dt)
+	}
+}
+
+// Visits this node and all its descendants (parents before children) with their world matrix,
+// parent is the world matrix of the parent of this node (the identity for the root)
+func (node *Node) Walk(parent mgl32.Mat4, visit func(node *Node, world mgl32.Mat4)) {
+	world := parent.Mul4(node.LocalMatrix())
+	visit(node, world)
+
+	for _, child := range node.Children {
+		child.Walk(world, visit)
+	}
+}
+
+// Visits every mesh in this branch of the graph with the model matrix it has to be drawn with
+// (the world matrix of its node combined with the model matrix of the mesh itself)
+func (node *Node) Draw(draw func(mesh objects.Drawable, model mgl32.Mat4)) {
+	parent := mgl32.Ident4()
+	if node.parent != nil {
+		parent = node.parent.WorldMatrix()
+	}
+
+	node.Walk(parent, func(current *Node, world mgl32.Mat4) {
+		if current.Mesh != nil {
+			draw(current.Mesh, world.Mul4(current.Mesh.GetModel()))
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package scene
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Returns where the origin of a node ends up in world space
+func worldPosition(node *Node) mgl32.Vec3 {
+	return node.WorldMatrix().Mul4x1(mgl32.Vec4{0, 0, 0, 1}).Vec3()
+}
+
+// Compares positions with an absolute tolerance (mgl32's is relative, so it fails next to 0)
+func near(a, b mgl32.Vec3) bool {
+	return a.Sub(b).Len() < 1e-5
+}
+
+func TestWorldMatrix(t *testing.T) {
+	parent := NewNode("parent", nil)
+	parent.Rotation = mgl32.Vec3{0, mgl32.DegToRad(90), 0}
+
+	child := parent.AddChild(NewNode("child", nil))
+	child.Position = mgl32.Vec3{2, 0, 0}
+
+	// Rotating the parent 90 degrees around y turns +x into -z
+	if position := worldPosition(child); !near(position, mgl32.Vec3{0, 0, -2}) {
+		t.Errorf("child is at %v, expected [0 0 -2]", position)
+	}
+
+	// The parent's translation and scale apply to the child's offset too
+	parent.Position = mgl32.Vec3{1, 1, 1}
+	parent.Scale = mgl32.Vec3{3, 3, 3}
+	if position := worldPosition(child); !near(position, mgl32.Vec3{1, 1, -5}) {
+		t.Errorf("child is at %v, expected [1 1 -5]", position)
+	}
+
+	// A grandchild combines all of its ancestors
+	grandchild := child.AddChild(NewNode("grandchild", nil))
+	grandchild.Position = mgl32.Vec3{0, 1, 0}
+	if position := worldPosition(grandchild); !near(position, mgl32.Vec3{1, 4, -5}) {
+		t.Errorf("grandchild is at %v, expected [1 4 -5]", position)
+	}
+
+	// The local matrix is translation * rotation * scale
+	local := NewNode("local", nil)
+	local.Position = mgl32.Vec3{0, 0, 5}
+	local.Rotation = mgl32.Vec3{0, 0, mgl32.DegToRad(90)}
+	local.Scale = mgl32.Vec3{2, 1, 1}
+	if point := local.LocalMatrix().Mul4x1(mgl32.Vec4{1, 0, 0, 1}).Vec3(); !near(point, mgl32.Vec3{0, 2, 5}) {
+		t.Errorf("local matrix moves [1 0 0] to %v, expected [0 2 5]", point)
+	}
+	if !local.WorldMatrix().ApproxEqual(local.LocalMatrix()) {
+		t.Errorf("world matrix of a root node isn't its local matrix")
+	}
+}
+
+func TestReparenting(t *testing.T) {
+	first, second := NewNode("first", nil), NewNode("second", nil)
+	child := first.AddChild(NewNode("child", nil))
+	sibling := first.AddChild(NewNode("sibling", nil))
+
+	if child.GetParent() != first || len(first.Children) != 2 {
+		t.Fatalf("child's parent is %v with %d children", child.GetParent(), len(first.Children))
+	}
+
+	// Adding it to another node moves it
+	second.AddChild(child)
+	if child.GetParent() != second {
+		t.Errorf("child's parent is %v, expected second", child.GetParent())
+	}
+	if len(first.Children) != 1 || first.Children[0] != sibling {
+		t.Errorf("first has children %v, expected only sibling", first.Children)
+	}
+	if len(second.Children) != 1 || second.Children[0] != child {
+		t.Errorf("second has children %v, expected only child", second.Children)
+	}
+
+	// A removed child has no parent, removing a node that isn't a child does nothing
+	second.RemoveChild(child)
+	if child.GetParent() != nil || len(second.Children) != 0 {
+		t.Errorf("removed child has parent %v, second has %d children", child.GetParent(), len(second.Children))
+	}
+	second.RemoveChild(sibling)
+	if sibling.GetParent() != first || len(first.Children) != 1 {
+		t.Errorf("removing a node from another parent changed it")
+	}
+
+	// Without a parent, the child is in world space again
+	child.Position = mgl32.Vec3{1, 2, 3}
+	if position := worldPosition(child); position != (mgl32.Vec3{1, 2, 3}) {
+		t.Errorf("removed child is at %v, expected [1 2 3]", position)
+	}
+}
+
+func TestAddChildRefusesLoops(t *testing.T) {
+	root := NewNode("root", nil)
+	child := root.AddChild(NewNode("child", nil))
+	grandchild := child.AddChild(NewNode("grandchild", nil))
+
+	if added := root.AddChild(root); added != nil {
+		t.Errorf("adding a node to itself returned %v, expected nil", added)
+	}
+	if added := grandchild.AddChild(root); added != nil {
+		t.Errorf("adding the root to its grandchild returned %v, expected nil", added)
+	}
+	if added := grandchild.AddChild(child); added != nil {
+		t.Errorf("adding a node to its child returned %v, expected nil", added)
+	}
+
+	// Nothing changed, and the matrices can still be calculated
+	if root.GetParent() != nil || child.GetParent() != root || grandchild.GetParent() != child {
+		t.Errorf("parents changed to %v, %v and %v", root.GetParent(), child.GetParent(), grandchild.GetParent())
+	}
+	if len(root.Children) != 1 || len(child.Children) != 1 || len(grandchild.Children) != 0 {
+		t.Errorf("children changed to %d, %d and %d", len(root.Children), len(child.Children), len(grandchild.Children))
+	}
+	grandchild.WorldMatrix()
+	root.Update(1)
+
+	// Moving a node up to its grandparent is not a loop
+	if added := root.AddChild(grandchild); added != grandchild || grandchild.GetParent() != root || len(child.Children) != 0 {
+		t.Errorf("moving the grandchild to the root returned %v with parent %v", added, grandchild.GetParent())
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := NewNode("root", nil)
+	planet := root.AddChild(NewNode("planet", nil))
+	moon := planet.AddChild(NewNode("moon", nil))
+	root.AddChild(NewNode("moon", nil))
+
+	if found := root.Find("root"); found != root {
+		t.Errorf("Find(root) is %v, expected the root", found)
+	}
+	// The first match in depth first order wins
+	if found := root.Find("moon"); found != moon {
+		t.Errorf("Find(moon) is %v, expected the planet's moon", found)
+	}
+	if found := planet.Find("root"); found != nil {
+		t.Errorf("Find only looks down the graph, found %v", found)
+	}
+	if found := root.Find("sun"); found != nil {
+		t.Errorf("Find(sun) is %v, expected nil", found)
+	}
+}
+
+func TestUpdateSpin(t *testing.T) {
+	root := NewNode("root", nil)
+	root.Spin = mgl32.Vec3{0, 1, 0}
+	child := root.AddChild(NewNode("child", nil))
+	child.Spin = mgl32.Vec3{2, 0, -0.5}
+	child.Rotation = mgl32.Vec3{0.1, 0, 0}
+
+	for i := 0; i < 4; i++ {
+		root.Update(0.25)
+	}
+
+	if !near(root.Rotation, mgl32.Vec3{0, 1, 0}) {
+		t.Errorf("root rotation is %v, expected [0 1 0]", root.Rotation)
+	}
+	if !near(child.Rotation, mgl32.Vec3{2.1, 0, -0.5}) {
+		t.Errorf("child rotation is %v, expected [2.1 0 -0.5]", child.Rotation)
+	}
+
+	// The child orbits with the parent's rotation on top of its own
+	child.Position = mgl32.Vec3{1, 0, 0}
+	expected := mgl32.Vec3{float32(math.Cos(1)), 0, -float32(math.Sin(1))}
+	if position := worldPosition(child); !near(position, expected) {
+		t.Errorf("child is at %v, expected %v", position, expected)
+	}
+}
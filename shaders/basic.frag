@@ -1,10 +1,34 @@
-// Minimal fragment shader
+// Fragment shader with per-fragment (Phong) lighting
 
 #version 330
 
 in vec4 fcolour;
+in vec3 fposition, fnormal;
+in vec2 ftexcoord;
+
+uniform uint colourmode, shademode;
+
+// Diffuse texture, multiplied with the colour when usetexture is set
+uniform sampler2D diffusemap;
+uniform bool usetexture;
+
+// Lights, materials and the shade() function
+#include "lighting.glsl"
+
 out vec4 outputColor;
+
 void main()
 {
-	outputColor = fcolour;
-}
\ No newline at end of file
+	vec4 texel = vec4(1.0);
+	if (usetexture)
+		texel = texture(diffusemap, ftexcoord);
+
+	// When shading per fragment the incoming colour is the unlit diffuse colour,
+	// when shading per vertex it is already lit so the texture can only modulate it
+	if (shademode == uint(2)) {
+		vec3 ambient_colour = (colourmode == uint(1)) ? fcolour.rgb : materialambient;
+		outputColor = vec4(shade(fposition, fnormal, fcolour.rgb * texel.rgb, ambient_colour * texel.rgb), fcolour.a * texel.a);
+	} else {
+		outputColor = fcolour * texel;
+	}
+}
@@ -1,4 +1,4 @@
-// Minimal vertex shader
+// Vertex shader with per-vertex (Gouraud) and per-fragment (Phong) lighting
 
 #version 330
 
@@ -6,27 +6,52 @@
 layout(location = 0) in vec3 position;
 layout(location = 1) in vec4 colour;
 layout(location = 2) in vec3 normal;
+layout(location = 3) in vec2 texcoord;
 
 // Uniform variables are passed in from the application
 uniform mat4 model, view, projection;
-uniform uint colourmode;
+uniform mat3 normalmatrix;
+uniform uint colourmode, shademode;
+
+// Lights, materials and the shade() function
+#include "lighting.glsl"
 
 // Output the vertex colour - to be rasterized into pixel fragments
 out vec4 fcolour;
 
+// Eye space position and normal, used when the lighting is done per fragment
+out vec3 fposition, fnormal;
+
+// Texture coordinates, the texture is sampled in the fragment shader
+out vec2 ftexcoord;
+
 void main()
 {
 	vec4 diffuse_colour;
+	vec3 ambient_colour;
 	vec4 position_h = vec4(position, 1.0);
 
-	if (colourmode == uint(1))
+	// The vertex colours replace the colours of the material
+	if (colourmode == uint(1)) {
 		diffuse_colour = colour;
+		ambient_colour = colour.rgb;
+	} else {
+		diffuse_colour = vec4(materialdiffuse, 1.0);
+		ambient_colour = materialambient;
+	}
+
+	// Eye space position and normal
+	vec4 P = view * model * position_h;
+	fposition = P.xyz;
+	fnormal = normalmatrix * normal;
+	ftexcoord = texcoord;
+
+	// Define the vertex colour, lit here when doing per-vertex lighting
+	if (shademode == uint(1))
+		fcolour = vec4(shade(fposition, fnormal, diffuse_colour.rgb, ambient_colour), diffuse_colour.a);
 	else
-		diffuse_colour = vec4(0.0, 1.0, 0, 1.0);
-
-	// Define the vertex colour
-	fcolour = diffuse_colour;
+		fcolour = diffuse_colour;
 
 	// Define the vertex position
-	gl_Position = (projection * view * model) * position_h;
-}
\ No newline at end of file
+	gl_Position = projection * P;
+}
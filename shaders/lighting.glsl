@@ -0,0 +1,74 @@
+// Lights, materials and the lighting model, shared by the vertex and the fragment shader
+// (included with #include "lighting.glsl", after #version)
+
+// Set by the app to objects.MAX_LIGHTS, the default is for tools compiling the shaders on their own
+#ifndef MAX_LIGHTS
+#define MAX_LIGHTS 8
+#endif
+
+// Lights (their positions and directions are already in eye space)
+struct Light {
+	uint kind;          // 1 directional, 2 point, 3 spot
+	vec4 position;      // w = 0 for directional lights, the direction towards the light
+	vec3 direction;     // Where spotlights point at
+	vec3 ambient, diffuse, specular;
+	vec3 attenuation;   // Constant, linear and quadratic attenuation factors
+	vec2 cone;          // Cosines of the inner and outer cone angles of spotlights
+};
+
+uniform Light lights[MAX_LIGHTS];
+uniform int numlights;
+
+// Specular term calculated with the Phong (1) or Blinn-Phong (2) model
+uniform uint specularmode;
+
+// Material of the object being drawn
+uniform vec3 materialambient, materialdiffuse, materialspecular, materialemissive;
+uniform float materialshininess;
+
+// Calculates the colour of a point in eye space with the Phong (or Blinn-Phong) lighting model, adding up every light
+vec3 shade(vec3 P, vec3 N, vec3 diffuse_albedo, vec3 ambient_albedo)
+{
+	N = normalize(N);
+	vec3 V = normalize(-P);
+	vec3 colour = materialemissive;
+
+	for (int i = 0; i < numlights; i++) {
+		vec3 L;
+		float attenuation = 1.0;
+
+		// Directional lights are infinitely far away, point lights and spotlights are attenuated by distance
+		if (lights[i].kind == uint(1)) {
+			L = normalize(lights[i].position.xyz);
+		} else {
+			vec3 light_vector = lights[i].position.xyz - P;
+			float distance = length(light_vector);
+			L = light_vector / distance;
+			attenuation = 1.0 / (lights[i].attenuation.x + lights[i].attenuation.y * distance + lights[i].attenuation.z * distance * distance);
+
+			// Spotlights fade out between the inner and the outer cone (Light.ConeAt calculates the same),
+			// with the same cones smoothstep is undefined so the edge is hard
+			if (lights[i].kind == uint(3)) {
+				float spot = dot(-L, normalize(lights[i].direction));
+				if (lights[i].cone.x > lights[i].cone.y)
+					attenuation *= smoothstep(lights[i].cone.y, lights[i].cone.x, spot);
+				else
+					attenuation *= step(lights[i].cone.y, spot);
+			}
+		}
+
+		float diffuse = max(dot(N, L), 0.0);
+		float specular = 0.0;
+		if (diffuse > 0.0) {
+			if (specularmode == uint(2))
+				specular = pow(max(dot(N, normalize(L + V)), 0.0), materialshininess * 4.0);
+			else
+				specular = pow(max(dot(reflect(-L, N), V), 0.0), materialshininess);
+		}
+
+		colour += lights[i].ambient * ambient_albedo
+			+ attenuation * (lights[i].diffuse * diffuse_albedo * diffuse + lights[i].specular * materialspecular * specular);
+	}
+
+	return colour;
+}
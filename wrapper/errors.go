@@ -0,0 +1,19 @@
+package wrapper
+
+import (
+	"fmt"
+)
+
+// The window or the OpenGL context couldn't be created (no display, or the driver doesn't support OpenGL 3.3)
+type ContextError struct {
+	Op  string // What was being done ("initialize glfw", "create the window"...)
+	Err error  // The error of glfw or gl
+}
+
+func (err *ContextError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", err.Op, err.Err)
+}
+
+func (err *ContextError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,425 @@
+package wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"../gpu"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+// When an action is triggered by its keys
+type ActionMode int
+
+const (
+	_ = iota // ignore first value by assigning to blank identifier
+	ACTION_PRESS ActionMode = 0 + iota // Once, when the key is pressed
+	ACTION_REPEAT // When the key is pressed and on every key repeat while it is held
+	ACTION_HOLD // On every update while the key is held
+)
+
+var actionModeNames = [...]string{
+	"_",
+	"press",
+	"repeat",
+	"hold",
+}
+
+// A key together with the modifier keys that have to be held with it
+type KeyBinding struct {
+	Key  glfw.Key
+	Mods glfw.ModifierKey
+}
+
+// A named action of the app, triggered by the keys bound to it
+type Action struct {
+	Name, Description string
+	Mode              ActionMode
+	Keys              []KeyBinding
+
+	handler           func()
+}
+
+// Maps key events to named actions, with the bindings loaded from a file
+type InputMap struct {
+	actions map[string]*Action
+	order   []string // Action names in the order they were added (for the help listing)
+	held    map[KeyBinding]bool
+}
+
+// Format of a bindings file, action names mapped to their keys (and optionally their mode)
+type bindingsFile map[string]struct {
+	Keys []string `json:"keys"`
+	Mode string   `json:"mode"`
+}
+
+// Modifier names used in bindings, like Ctrl+S (names aren't case sensitive)
+var modifierNames = []struct {
+	name string
+	mod  glfw.ModifierKey
+}{
+	{"Ctrl", glfw.ModControl},
+	{"Alt", glfw.ModAlt},
+	{"Shift", glfw.ModShift},
+	{"Super", glfw.ModSuper},
+}
+
+// Names of the keys that aren't letters, numbers or function keys (names aren't case sensitive)
+var keyNames = map[string]glfw.Key{
+	"Space":        glfw.KeySpace,
+	"Apostrophe":   glfw.KeyApostrophe,
+	"Comma":        glfw.KeyComma,
+	"Minus":        glfw.KeyMinus,
+	"Period":       glfw.KeyPeriod,
+	"Slash":        glfw.KeySlash,
+	"Semicolon":    glfw.KeySemicolon,
+	"Equal":        glfw.KeyEqual,
+	"LeftBracket":  glfw.KeyLeftBracket,
+	"Backslash":    glfw.KeyBackslash,
+	"RightBracket": glfw.KeyRightBracket,
+	"GraveAccent":  glfw.KeyGraveAccent,
+	"Escape":       glfw.KeyEscape,
+	"Enter":        glfw.KeyEnter,
+	"Tab":          glfw.KeyTab,
+	"Backspace":    glfw.KeyBackspace,
+	"Insert":       glfw.KeyInsert,
+	"Delete":       glfw.KeyDelete,
+	"Right":        glfw.KeyRight,
+	"Left":         glfw.KeyLeft,
+	"Down":         glfw.KeyDown,
+	"Up":           glfw.KeyUp,
+	"PageUp":       glfw.KeyPageUp,
+	"PageDown":     glfw.KeyPageDown,
+	"Home":         glfw.KeyHome,
+	"End":          glfw.KeyEnd,
+	"KPAdd":        glfw.KeyKPAdd,
+	"KPSubtract":   glfw.KeyKPSubtract,
+}
+
+func NewInputMap() *InputMap {
+	return &InputMap{
+		make(map[string]*Action), // actions
+		nil, // order
+		make(map[KeyBinding]bool), // held
+	}
+}
+
+//
+// Add Action
+// Registers an action, it isn't triggered until keys are bound to it
+//
+// @param name (string) the name used in the bindings file
+// @param description (string) the description shown in the help listing
+// @param mode (ActionMode) when the action is triggered (can be changed in the bindings file)
+// @param handler (func()) the function called when the action is triggered
+//
+func (input *InputMap) AddAction(name, description string, mode ActionMode, handler func()) {
+	if _, exists := input.actions[name]; !exists {
+		input.order = append(input.order, name)
+	}
+
+	input.actions[name] = &Action{name, description, mode, nil, handler}
+}
+
+//
+// Bind
+// Binds keys to an action, like "Q", "Shift+Q" or "Ctrl+Alt+F1"
+//
+// @param name (string) the name of the action
+// @param keys (...string) the keys
+//
+// @return error (error) the error (if the action or a key doesn't exist)
+//
+func (input *InputMap) Bind(name string, keys ...string) error {
+	action, found := input.actions[name]
+	if !found {
+		return fmt.Errorf("unknown action %q", name)
+	}
+
+	for _, key := range keys {
+		binding, err := ParseKeyBinding(key)
+		if err != nil {
+			return fmt.Errorf("action %q: %v", name, err)
+		}
+		action.Keys = append(action.Keys, binding)
+	}
+
+	return nil
+}
+
+//
+// Load Bindings
+// Loads the key bindings from a JSON file, replacing the keys of every action in the file.
+// The file maps action names to their keys and (optionally) their mode:
+// { "rotate_x_inc": { "keys": ["Q", "Shift+Up"], "mode": "hold" } }
+// The whole file is checked first, if it has an error none of the bindings change.
+//
+// @param path (string) the path to the bindings file
+//
+// @return error (error) the error (if any)
+//
+func (input *InputMap) LoadBindings(path string) error {
+	content, err := gpu.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var bindings bindingsFile
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(content, "\x00")), &bindings); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	// Sorted, so a file with several errors always reports the same one
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	modes := make(map[string]ActionMode, len(names))
+	keys := make(map[string][]KeyBinding, len(names))
+	for _, name := range names {
+		action, found := input.actions[name]
+		if !found {
+			return fmt.Errorf("%s: unknown action %q", path, name)
+		}
+
+		modes[name] = action.Mode
+		if bindings[name].Mode != "" {
+			mode, err := parseActionMode(bindings[name].Mode)
+			if err != nil {
+				return fmt.Errorf("%s: action %q: %v", path, name, err)
+			}
+			modes[name] = mode
+		}
+
+		for _, key := range bindings[name].Keys {
+			binding, err := ParseKeyBinding(key)
+			if err != nil {
+				return fmt.Errorf("%s: action %q: %v", path, name, err)
+			}
+			keys[name] = append(keys[name], binding)
+		}
+	}
+
+	for _, name := range names {
+		input.actions[name].Mode = modes[name]
+		input.actions[name].Keys = keys[name]
+	}
+
+	return nil
+}
+
+//
+// Key Callback
+// Triggers the actions bound to a key (set it as the key callback of the window)
+//
+// @param window (*glfw.Window) a pointer to the window
+// @param key (glfw.Key) the pressed key
+// @param scancode (int) the scancode
+// @param action (glfw.Action) the state of the key
+// @param mods (glfw.ModifierKey) the pressed modified keys.
+//
+func (input *InputMap) KeyCallback(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	// A released key stops all the hold actions bound to it, whatever modifiers are held now
+	if action == glfw.Release {
+		for binding := range input.held {
+			if binding.Key == key {
+				delete(input.held, binding)
+			}
+		}
+		return
+	}
+
+	binding := KeyBinding{key, mods}
+	for _, name := range input.order {
+		current := input.actions[name]
+		if !current.isBoundTo(binding) {
+			continue
+		}
+
+		switch current.Mode {
+		case ACTION_PRESS:
+			if action == glfw.Press {
+				current.handler()
+			}
+		case ACTION_REPEAT:
+			current.handler()
+		case ACTION_HOLD:
+			input.held[binding] = true
+		}
+	}
+}
+
+//
+// Update
+// Triggers the hold actions of the keys that are held (call it from the update callback)
+//
+func (input *InputMap) Update() {
+	for _, name := range input.order {
+		action := input.actions[name]
+		if action.Mode != ACTION_HOLD {
+			continue
+		}
+
+		for _, binding := range action.Keys {
+			if input.held[binding] {
+				action.handler()
+				break
+			}
+		}
+	}
+}
+
+//
+// Help
+// Returns a listing of the actions and the keys bound to them
+//
+// @return help (string) the listing, one action per line
+//
+func (input *InputMap) Help() string {
+	var help strings.Builder
+	help.WriteString("Key bindings:\n")
+
+	for _, name := range input.order {
+		action := input.actions[name]
+
+		keys := make([]string, 0, len(action.Keys))
+		for _, binding := range action.Keys {
+			keys = append(keys, binding.String())
+		}
+		if len(keys) == 0 {
+			keys = append(keys, "(unbound)")
+		}
+
+		fmt.Fprintf(&help, "  %-16s %-24s %-7s %s\n", strings.Join(keys, ", "), name, action.Mode, action.Description)
+	}
+
+	return help.String()
+}
+
+//
+// Parse Key Binding
+// Parses a key with optional modifiers, like "Q", "Shift+Q" or "Ctrl+Alt+F1" (names aren't case sensitive)
+//
+// @param name (string) the key
+//
+// @return binding (KeyBinding) the key and its modifiers
+// @return error (error) the error (if a name isn't known or a modifier is repeated)
+//
+func ParseKeyBinding(name string) (KeyBinding, error) {
+	parts := strings.Split(name, "+")
+	binding := KeyBinding{glfw.KeyUnknown, 0}
+
+	for _, modifier := range parts[:len(parts) - 1] {
+		mod, err := parseModifier(strings.TrimSpace(modifier))
+		if err != nil {
+			return binding, fmt.Errorf("%v in %q", err, name)
+		}
+		if binding.Mods & mod != 0 {
+			return binding, fmt.Errorf("modifier %q repeated in %q", strings.TrimSpace(modifier), name)
+		}
+		binding.Mods |= mod
+	}
+
+	key, err := parseKey(strings.TrimSpace(parts[len(parts) - 1]))
+	if err != nil {
+		return binding, err
+	}
+
+	binding.Key = key
+	return binding, nil
+}
+
+func (binding KeyBinding) String() string {
+	var parts []string
+	for _, modifier := range modifierNames {
+		if binding.Mods & modifier.mod != 0 {
+			parts = append(parts, modifier.name)
+		}
+	}
+
+	return strings.Join(append(parts, keyName(binding.Key)), "+")
+}
+
+func (mode ActionMode) String() string {
+	return actionModeNames[mode]
+}
+
+// Returns true if the binding is one of the keys of the action (the modifiers have to match exactly)
+func (action *Action) isBoundTo(binding KeyBinding) bool {
+	for _, current := range action.Keys {
+		if current == binding {
+			return true
+		}
+	}
+	return false
+}
+
+func parseActionMode(name string) (ActionMode, error) {
+	for mode, modeName := range actionModeNames {
+		if mode > 0 && modeName == strings.ToLower(name) {
+			return ActionMode(mode), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown mode %q (press, repeat or hold)", name)
+}
+
+func parseModifier(name string) (glfw.ModifierKey, error) {
+	for _, modifier := range modifierNames {
+		if strings.EqualFold(modifier.name, name) {
+			return modifier.mod, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown modifier %q", name)
+}
+
+// Parses a key name: a letter, a number, F1 to F12 or one of keyNames
+func parseKey(name string) (glfw.Key, error) {
+	upper := strings.ToUpper(name)
+
+	if len(upper) == 1 && upper[0] >= 'A' && upper[0] <= 'Z' {
+		return glfw.KeyA + glfw.Key(upper[0] - 'A'), nil
+	}
+	if len(upper) == 1 && upper[0] >= '0' && upper[0] <= '9' {
+		return glfw.Key0 + glfw.Key(upper[0] - '0'), nil
+	}
+
+	if len(upper) > 1 && upper[0] == 'F' {
+		if function, err := strconv.Atoi(upper[1:]); err == nil && function >= 1 && function <= 12 {
+			return glfw.KeyF1 + glfw.Key(function - 1), nil
+		}
+	}
+
+	for keyName, key := range keyNames {
+		if strings.EqualFold(keyName, name) {
+			return key, nil
+		}
+	}
+
+	return glfw.KeyUnknown, fmt.Errorf("unknown key %q", name)
+}
+
+// Returns the name of a key, as it is written in a bindings file
+func keyName(key glfw.Key) string {
+	switch {
+	case key >= glfw.KeyA && key <= glfw.KeyZ:
+		return string(rune('A' + (key - glfw.KeyA)))
+	case key >= glfw.Key0 && key <= glfw.Key9:
+		return string(rune('0' + (key - glfw.Key0)))
+	case key >= glfw.KeyF1 && key <= glfw.KeyF12:
+		return fmt.Sprintf("F%d", key - glfw.KeyF1 + 1)
+	}
+
+	for name, current := range keyNames {
+		if current == key {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("Key%d", int(key))
+}
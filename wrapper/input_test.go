@@ -0,0 +1,134 @@
+package wrapper
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"../gpu"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+func TestParseKeyBinding(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected KeyBinding
+	}{
+		{"Q", KeyBinding{glfw.KeyQ, 0}},
+		{"q", KeyBinding{glfw.KeyQ, 0}},
+		{"7", KeyBinding{glfw.Key7, 0}},
+		{"F12", KeyBinding{glfw.KeyF12, 0}},
+		{"pageup", KeyBinding{glfw.KeyPageUp, 0}},
+		{"Shift+Q", KeyBinding{glfw.KeyQ, glfw.ModShift}},
+		{"shift+q", KeyBinding{glfw.KeyQ, glfw.ModShift}},
+		{"SHIFT + Up", KeyBinding{glfw.KeyUp, glfw.ModShift}},
+		{"Ctrl+Alt+Delete", KeyBinding{glfw.KeyDelete, glfw.ModControl | glfw.ModAlt}},
+		{"Alt+Ctrl+Delete", KeyBinding{glfw.KeyDelete, glfw.ModControl | glfw.ModAlt}},
+	}
+	for _, test := range tests {
+		binding, err := ParseKeyBinding(test.name)
+		if err != nil {
+			t.Errorf("%q: %v", test.name, err)
+		} else if binding != test.expected {
+			t.Errorf("%q is %v, expected %v", test.name, binding, test.expected)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"Ctrl+",
+		"F13",
+		"F0",
+		"Hyper+Q",
+		"Unknown",
+		"QQ",
+		"Shift+Shift+Q",
+		"Ctrl+ctrl+Q",
+	}
+	for _, name := range invalid {
+		if binding, err := ParseKeyBinding(name); err == nil {
+			t.Errorf("%q is %v, expected an error", name, binding)
+		}
+	}
+}
+
+func TestKeyBindingString(t *testing.T) {
+	for _, name := range []string{"Q", "Shift+Q", "Ctrl+Alt+Delete", "F5", "Ctrl+KPAdd"} {
+		binding, err := ParseKeyBinding(name)
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if written := binding.String(); written != name {
+			t.Errorf("%q is written as %q", name, written)
+		}
+	}
+}
+
+// Makes an input map with two actions and loads a bindings file from memory
+func loadBindingsString(t *testing.T, content string) (*InputMap, error) {
+	previous := gpu.Assets()
+	gpu.SetAssets(fstest.MapFS{"bindings.json": &fstest.MapFile{Data: []byte(content)}})
+	defer gpu.SetAssets(previous)
+
+	input := NewInputMap()
+	input.AddAction("quit", "Close the app", ACTION_PRESS, func() {})
+	input.AddAction("scale_up", "Make the objects bigger", ACTION_PRESS, func() {})
+	if err := input.Bind("quit", "Escape"); err != nil {
+		t.Fatal(err)
+	}
+	if err := input.Bind("scale_up", "Equal"); err != nil {
+		t.Fatal(err)
+	}
+
+	return input, input.LoadBindings("bindings.json")
+}
+
+func TestLoadBindings(t *testing.T) {
+	input, err := loadBindingsString(t, `{ "scale_up": { "keys": ["Shift+Equal", "KPAdd"], "mode": "Repeat" } }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scaleUp := input.actions["scale_up"]
+	if scaleUp.Mode != ACTION_REPEAT {
+		t.Errorf("mode is %v, expected repeat", scaleUp.Mode)
+	}
+	if len(scaleUp.Keys) != 2 || scaleUp.Keys[0] != (KeyBinding{glfw.KeyEqual, glfw.ModShift}) || scaleUp.Keys[1] != (KeyBinding{glfw.KeyKPAdd, 0}) {
+		t.Errorf("keys are %v, expected Shift+Equal and KPAdd", scaleUp.Keys)
+	}
+
+	// The actions that aren't in the file keep their keys and mode
+	if quit := input.actions["quit"]; len(quit.Keys) != 1 || quit.Keys[0].Key != glfw.KeyEscape || quit.Mode != ACTION_PRESS {
+		t.Errorf("quit is %+v, expected Escape (press)", quit)
+	}
+}
+
+func TestLoadBindingsErrors(t *testing.T) {
+	files := map[string]string{
+		"unknown action": `{ "quit": { "keys": ["Q"] }, "jump": { "keys": ["Space"] } }`,
+		"unknown mode": `{ "quit": { "keys": ["Q"] }, "scale_up": { "keys": ["E"], "mode": "toggle" } }`,
+		"unknown key": `{ "quit": { "keys": ["Q"] }, "scale_up": { "keys": ["Ctrl+Nope"] } }`,
+		"invalid json": `{ "quit": `,
+	}
+
+	for name, content := range files {
+		input, err := loadBindingsString(t, content)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+
+		// Nothing changes, not even the actions before the error
+		quit, scaleUp := input.actions["quit"], input.actions["scale_up"]
+		if len(quit.Keys) != 1 || quit.Keys[0].Key != glfw.KeyEscape {
+			t.Errorf("%s: quit keys changed to %v", name, quit.Keys)
+		}
+		if len(scaleUp.Keys) != 1 || scaleUp.Keys[0].Key != glfw.KeyEqual || scaleUp.Mode != ACTION_PRESS {
+			t.Errorf("%s: scale_up changed to %v (%v)", name, scaleUp.Keys, scaleUp.Mode)
+		}
+	}
+
+	if _, err := loadBindingsString(t, `{}`); err != nil {
+		t.Errorf("empty file: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package wrapper
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+
+	"../imaging"
+	"../gpu"
+
+	"github.com/go-gl/gl/all-core/gl"
+)
+
+// Framebuffer object with a colour and a depth attachment, used to render without drawing to the window
+type Framebuffer struct {
+	Width, Height int
+
+	framebuffer, colourBuffer, depthBuffer uint32
+}
+
+//
+// New Framebuffer
+// Creates a framebuffer object with an RGBA colour renderbuffer and a depth renderbuffer.
+//
+// @param width (int) the width of the framebuffer in pixels
+// @param height (int) the height of the framebuffer in pixels
+//
+// @return framebuffer (*Framebuffer) a pointer to the framebuffer
+// @return error (error) the error (if any)
+//
+func NewFramebuffer(width, height int) (*Framebuffer, error) {
+	framebuffer := &Framebuffer{width, height, 0, 0, 0}
+
+	// Creates the colour attachment
+	gl.GenRenderbuffers(1, &framebuffer.colourBuffer)
+	gpu.TrackResource(gpu.RESOURCE_RENDERBUFFER, framebuffer.colourBuffer, "offscreen colour buffer")
+	gl.BindRenderbuffer(gl.RENDERBUFFER, framebuffer.colourBuffer)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA8, int32(width), int32(height))
+
+	// Creates the depth attachment
+	gl.GenRenderbuffers(1, &framebuffer.depthBuffer)
+	gpu.TrackResource(gpu.RESOURCE_RENDERBUFFER, framebuffer.depthBuffer, "offscreen depth buffer")
+	gl.BindRenderbuffer(gl.RENDERBUFFER, framebuffer.depthBuffer)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	// Creates the framebuffer and attaches the renderbuffers to it
+	gl.GenFramebuffers(1, &framebuffer.framebuffer)
+	gpu.TrackResource(gpu.RESOURCE_FRAMEBUFFER, framebuffer.framebuffer, "offscreen framebuffer")
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer.framebuffer)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, framebuffer.colourBuffer)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, framebuffer.depthBuffer)
+
+	// Checks that the driver can render to this combination of attachments
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		framebuffer.Release()
+		return nil, fmt.Errorf("framebuffer is incomplete (status 0x%x)", status)
+	}
+
+	return framebuffer, nil
+}
+
+//
+// Bind
+// Makes the framebuffer the render target and sets the viewport to its size
+//
+func (framebuffer *Framebuffer) Bind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer.framebuffer)
+	gl.Viewport(0, 0, int32(framebuffer.Width), int32(framebuffer.Height))
+}
+
+//
+// Unbind
+// Makes the window the render target again
+//
+func (framebuffer *Framebuffer) Unbind() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+}
+
+//
+// Read Pixels
+// Reads the colour attachment back from the GPU.
+//
+// @return image (*image.RGBA) the contents of the framebuffer (top row first)
+//
+func (framebuffer *Framebuffer) ReadPixels() *image.RGBA {
+	pixels := image.NewRGBA(image.Rect(0, 0, framebuffer.Width, framebuffer.Height))
+
+	// Waits for the rendering to finish and reads the pixels without row padding
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, framebuffer.framebuffer)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(framebuffer.Width), int32(framebuffer.Height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels.Pix))
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+
+	// OpenGL returns the bottom row first, images expect the top row first
+	imaging.FlipVertically(pixels)
+	return pixels
+}
+
+//
+// Release
+// Deletes the framebuffer and its attachments
+//
+func (framebuffer *Framebuffer) Release() {
+	gl.DeleteFramebuffers(1, &framebuffer.framebuffer)
+	gl.DeleteRenderbuffers(1, &framebuffer.colourBuffer)
+	gl.DeleteRenderbuffers(1, &framebuffer.depthBuffer)
+	gpu.UntrackResource(gpu.RESOURCE_FRAMEBUFFER, framebuffer.framebuffer)
+	gpu.UntrackResource(gpu.RESOURCE_RENDERBUFFER, framebuffer.colourBuffer)
+	gpu.UntrackResource(gpu.RESOURCE_RENDERBUFFER, framebuffer.depthBuffer)
+	framebuffer.framebuffer, framebuffer.colourBuffer, framebuffer.depthBuffer = 0, 0, 0
+}
+
+//
+// Frame Path
+// Returns the path a captured frame is saved to.
+// If the pattern has a format verb (like frame_%03d.png) it is formatted with the frame number,
+// otherwise the frame number is added before the extension (unless only one frame is rendered).
+//
+// @param pattern (string) the path pattern
+// @param frame (int) the frame number (starting from 0)
+// @param frames (int) the number of frames that are rendered
+//
+// @return path (string) the path to the frame
+//
+func FramePath(pattern string, frame, frames int) string {
+	if strings.Contains(pattern, "%") {
+		return fmt.Sprintf(pattern, frame)
+	}
+
+	if frames == 1 {
+		return pattern
+	}
+
+	extension := filepath.Ext(pattern)
+	return fmt.Sprintf("%s_%03d%s", strings.TrimSuffix(pattern, extension), frame, extension)
+}
@@ -0,0 +1,242 @@
+package wrapper
+
+import (
+	"fmt"
+	"sort"
+
+	"../gpu"
+
+	"github.com/go-gl/gl/all-core/gl"
+	"github.com/go-gl/glfw/v3.1/glfw"
+)
+
+// Number of frames the rolling averages and percentiles are calculated from
+const statsHistory = 120
+
+// Statistics of one frame
+type FrameStats struct {
+	Interval  float64 // Seconds since the previous frame started
+	CPUTime   float64 // Seconds spent in the update and render callbacks
+	GPUTime   float64 // Seconds the GPU spent rendering the frame (0 if it isn't known yet)
+	DrawCalls int
+	Triangles int
+}
+
+// Summary of the statistics of the last frames
+type StatsReport struct {
+	Frames                            int
+	FPS                               float64
+	CPUAverage, CPUP50, CPUP95, CPUP99 float64 // Milliseconds
+	GPUAverage, GPUP50, GPUP95, GPUP99 float64 // Milliseconds
+	DrawCalls, Triangles              float64 // Average per frame
+}
+
+// Collects the statistics of every frame, the GPU time is measured with timer queries
+type Stats struct {
+	history        []FrameStats // Ring buffer with the last frames
+	next           int          // Position of the next frame in the history
+
+	current        FrameStats
+	frameStart     float64
+	previousStart  float64
+
+	// Two queries are used in turns, so the result of the previous frame is read while the current one is measured
+	queries        [2]uint32
+	queryFrame     [2]int // Position in the history of the frame each query measured (-1 if none)
+	activeQuery    int
+
+	ReportInterval float64 // Seconds between reports
+	lastReport     float64
+	Visible        bool    // Whether the reports are printed and shown in the window title
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		make([]FrameStats, 0, statsHistory), 0, // history, next
+		FrameStats{}, 0, 0, // current, frame start, previous frame start
+		[2]uint32{0, 0}, [2]int{-1, -1}, 0, // queries, query frames, active query
+		2.0, 0, false, // report interval, last report, visible
+	}
+}
+
+//
+// Begin Frame
+// Starts measuring a frame (call before the update and render callbacks)
+//
+func (stats *Stats) BeginFrame() {
+	if stats.queries[0] == 0 {
+		gl.GenQueries(2, &stats.queries[0])
+		gpu.TrackResource(gpu.RESOURCE_QUERY, stats.queries[0], "frame timer query 0")
+		gpu.TrackResource(gpu.RESOURCE_QUERY, stats.queries[1], "frame timer query 1")
+	}
+
+	gpu.TakeDrawCounts()
+	stats.current = FrameStats{}
+	stats.frameStart = glfw.GetTime()
+
+	if stats.previousStart > 0 {
+		stats.current.Interval = stats.frameStart - stats.previousStart
+	}
+	stats.previousStart = stats.frameStart
+
+	// Reads the result of the query that was used two frames ago, before using it again
+	stats.collectQuery(stats.activeQuery, true)
+	gl.BeginQuery(gl.TIME_ELAPSED, stats.queries[stats.activeQuery])
+}
+
+//
+// End Frame
+// Finishes measuring a frame (call after the render callback, before swapping the buffers)
+//
+func (stats *Stats) EndFrame() {
+	gl.EndQuery(gl.TIME_ELAPSED)
+
+	stats.current.CPUTime = glfw.GetTime() - stats.frameStart
+	stats.current.DrawCalls, stats.current.Triangles = gpu.TakeDrawCounts()
+
+	position := stats.add(stats.current)
+	stats.queryFrame[stats.activeQuery] = position
+	stats.activeQuery = 1 - stats.activeQuery
+
+	// The previous frame's query has usually finished by now, so its result can be read without waiting
+	stats.collectQuery(stats.activeQuery, false)
+}
+
+//
+// Report
+// Summarizes the statistics of the frames in the history
+//
+// @return report (StatsReport) the averages and percentiles
+//
+func (stats *Stats) Report() StatsReport {
+	report := StatsReport{Frames: len(stats.history)}
+	if report.Frames == 0 {
+		return report
+	}
+
+	cpuTimes := make([]float64, 0, report.Frames)
+	gpuTimes := make([]float64, 0, report.Frames)
+	var elapsed float64
+
+	for _, frame := range stats.history {
+		elapsed += frame.Interval
+		cpuTimes = append(cpuTimes, frame.CPUTime * 1000)
+		if frame.GPUTime > 0 {
+			gpuTimes = append(gpuTimes, frame.GPUTime * 1000)
+		}
+		report.DrawCalls += float64(frame.DrawCalls)
+		report.Triangles += float64(frame.Triangles)
+	}
+
+	if elapsed > 0 {
+		report.FPS = float64(report.Frames) / elapsed
+	}
+
+	report.DrawCalls /= float64(report.Frames)
+	report.Triangles /= float64(report.Frames)
+	report.CPUAverage, report.CPUP50, report.CPUP95, report.CPUP99 = summarize(cpuTimes)
+	report.GPUAverage, report.GPUP50, report.GPUP95, report.GPUP99 = summarize(gpuTimes)
+
+	return report
+}
+
+//
+// Should Report
+// Returns true when the report is visible and the report interval has passed since the last report
+//
+// @return report (bool) whether a report is due
+//
+func (stats *Stats) ShouldReport() bool {
+	now := glfw.GetTime()
+	if !stats.Visible || now - stats.lastReport < stats.ReportInterval {
+		return false
+	}
+
+	stats.lastReport = now
+	return true
+}
+
+//
+// Release
+// Deletes the timer queries (they are created again by the next frame)
+//
+func (stats *Stats) Release() {
+	if stats.queries[0] != 0 {
+		gl.DeleteQueries(2, &stats.queries[0])
+		gpu.UntrackResource(gpu.RESOURCE_QUERY, stats.queries[0])
+		gpu.UntrackResource(gpu.RESOURCE_QUERY, stats.queries[1])
+		stats.queries = [2]uint32{0, 0}
+	}
+}
+
+func (report StatsReport) String() string {
+	return fmt.Sprintf(
+		"%.0f fps | cpu %.2fms (p50 %.2f p95 %.2f p99 %.2f) | gpu %.2fms (p50 %.2f p95 %.2f p99 %.2f) | %.0f draw calls | %.0f triangles",
+		report.FPS,
+		report.CPUAverage, report.CPUP50, report.CPUP95, report.CPUP99,
+		report.GPUAverage, report.GPUP50, report.GPUP95, report.GPUP99,
+		report.DrawCalls, report.Triangles,
+	)
+}
+
+// Adds a frame to the history, returning its position
+func (stats *Stats) add(frame FrameStats) int {
+	position := stats.next
+	if len(stats.history) < statsHistory {
+		stats.history = append(stats.history, frame)
+	} else {
+		stats.history[position] = frame
+	}
+
+	stats.next = (position + 1) % statsHistory
+	return position
+}
+
+// Stores the GPU time measured by a query in its frame, if wait is false it only does it if the result is ready
+func (stats *Stats) collectQuery(query int, wait bool) {
+	frame := stats.queryFrame[query]
+	if frame < 0 {
+		return
+	}
+
+	if !wait {
+		var available int32
+		gl.GetQueryObjectiv(stats.queries[query], gl.QUERY_RESULT_AVAILABLE, &available)
+		if available == gl.FALSE {
+			return
+		}
+	}
+
+	var elapsed uint64
+	gl.GetQueryObjectui64v(stats.queries[query], gl.QUERY_RESULT, &elapsed)
+	stats.history[frame].GPUTime = float64(elapsed) / 1e9
+	stats.queryFrame[query] = -1
+}
+
+// Returns the average, median, 95th and 99th percentiles of the values
+func summarize(values []float64) (average, p50, p95, p99 float64) {
+	if len(values) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	for _, value := range values {
+		average += value
+	}
+	average /= float64(len(values))
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return average, percentile(sorted, 0.50), percentile(sorted, 0.95), percentile(sorted, 0.99)
+}
+
+// Returns the value below which the given fraction of the sorted values are (nearest rank)
+func percentile(sorted []float64, fraction float64) float64 {
+	rank := int(fraction * float64(len(sorted)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank - 1]
+}
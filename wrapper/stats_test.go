@@ -0,0 +1,55 @@
+package wrapper
+
+import (
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	if average, p50, p95, p99 := summarize(nil); average != 0 || p50 != 0 || p95 != 0 || p99 != 0 {
+		t.Errorf("summary of no values is %v %v %v %v, expected zeros", average, p50, p95, p99)
+	}
+
+	// 100 values, in reverse so they have to be sorted
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(100 - i)
+	}
+
+	average, p50, p95, p99 := summarize(values)
+	if average != 50.5 {
+		t.Errorf("average is %v, expected 50.5", average)
+	}
+	if p50 != 50 || p95 != 95 || p99 != 99 {
+		t.Errorf("percentiles are %v %v %v, expected 50 95 99", p50, p95, p99)
+	}
+	if values[0] != 100 || values[99] != 1 {
+		t.Errorf("summarize changed the order of the values")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	single := []float64{7}
+	for _, fraction := range []float64{0, 0.5, 0.99, 1} {
+		if value := percentile(single, fraction); value != 7 {
+			t.Errorf("percentile %v of a single value is %v, expected 7", fraction, value)
+		}
+	}
+
+	sorted := []float64{1, 2, 3, 4}
+	tests := []struct {
+		fraction float64
+		expected float64
+	}{
+		{0, 1},    // The lowest rank is the first value
+		{0.25, 1},
+		{0.5, 2},
+		{0.6, 2},  // 2.4 rounds down to the 2nd value
+		{0.7, 3},  // 2.8 rounds up to the 3rd value
+		{1, 4},
+	}
+	for _, test := range tests {
+		if value := percentile(sorted, test.fraction); value != test.expected {
+			t.Errorf("percentile %v is %v, expected %v", test.fraction, value, test.expected)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package wrapper
+
+import (
+	"math"
+)
+
+// Updates are never run for more than this many seconds per frame, so a slow frame (or a breakpoint)
+// doesn't make the loop fall behind running updates forever
+const maxFrameDelta = 0.25
+
+// Most updates run in one frame, if the updates are slower than the real time the loop drops the time
+// it can't catch up with instead of running more updates every frame (a death spiral)
+const maxUpdatesPerFrame = 16
+
+//
+// Step Timestep
+// Adds the time of a frame to the accumulator and takes the fixed timesteps that fit in it
+//
+// @param accumulator (float64) the seconds left over from the previous frames
+// @param delta (float64) the seconds since the previous frame (clamped to 0 to maxFrameDelta)
+// @param timestep (float64) the seconds each update advances the app (more than 0)
+//
+// @return updates (int) the number of updates to run in this frame (at most maxUpdatesPerFrame)
+// @return accumulator (float64) the seconds left for the next frame (less than a timestep)
+//
+func stepTimestep(accumulator, delta, timestep float64) (int, float64) {
+	if delta > maxFrameDelta {
+		delta = maxFrameDelta
+	} else if !(delta > 0) {
+		delta = 0
+	}
+	accumulator += delta
+
+	updates := 0
+	for accumulator >= timestep && updates < maxUpdatesPerFrame {
+		accumulator -= timestep
+		updates++
+	}
+
+	// The time that didn't fit in this frame's updates is dropped, so the next frames don't fall further behind
+	if accumulator >= timestep {
+		accumulator = math.Mod(accumulator, timestep)
+	}
+
+	return updates, accumulator
+}
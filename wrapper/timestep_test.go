@@ -0,0 +1,112 @@
+package wrapper
+
+import (
+	"math"
+	"testing"
+)
+
+// A clock that advances by the given seconds on every frame, running the loop's stepping like StartLoop does
+type fakeClock struct {
+	now         float64
+	previous    float64
+	accumulator float64
+	updates     int // Updates run since the clock started
+}
+
+// Advances the clock by a frame, returning the updates of the frame and the alpha
+func (clock *fakeClock) frame(seconds, timestep float64) (int, float64) {
+	clock.now += seconds
+
+	var updates int
+	updates, clock.accumulator = stepTimestep(clock.accumulator, clock.now - clock.previous, timestep)
+	clock.previous = clock.now
+	clock.updates += updates
+
+	return updates, clock.accumulator / timestep
+}
+
+func TestStepTimestepKeepsUpWithTheClock(t *testing.T) {
+	clock := &fakeClock{}
+	timestep := 1.0 / 60.0
+
+	// 120 frames at 50 fps run 2.4 seconds of 60 Hz updates
+	for frame := 0; frame < 120; frame++ {
+		updates, alpha := clock.frame(1.0 / 50.0, timestep)
+		if updates < 1 || updates > 2 {
+			t.Fatalf("frame %d runs %d updates, expected 1 or 2", frame, updates)
+		}
+		if alpha < 0 || alpha >= 1 {
+			t.Fatalf("frame %d has alpha %v, expected between 0 and 1", frame, alpha)
+		}
+	}
+
+	if math.Abs(float64(clock.updates) * timestep + clock.accumulator - clock.now) > 1e-9 {
+		t.Errorf("%d updates and %v seconds left after %v seconds", clock.updates, clock.accumulator, clock.now)
+	}
+	if clock.updates != 144 {
+		t.Errorf("%d updates, expected 144", clock.updates)
+	}
+}
+
+func TestStepTimestepFastFrames(t *testing.T) {
+	clock := &fakeClock{}
+
+	// At 240 fps a 60 Hz update runs every 4 frames, the alpha grows in between
+	expected := []int{0, 0, 0, 1, 0, 0, 0, 1}
+	for frame, want := range expected {
+		updates, alpha := clock.frame(1.0 / 240.0, 1.0 / 60.0)
+		if updates != want {
+			t.Errorf("frame %d runs %d updates, expected %d", frame, updates, want)
+		}
+		if wantAlpha := float64((frame + 1) % 4) / 4; math.Abs(alpha - wantAlpha) > 1e-9 {
+			t.Errorf("frame %d has alpha %v, expected %v", frame, alpha, wantAlpha)
+		}
+	}
+}
+
+func TestStepTimestepLongFrame(t *testing.T) {
+	// A 5 second pause (a breakpoint) only runs maxFrameDelta seconds of updates
+	updates, accumulator := stepTimestep(0, 5, 0.1)
+	if updates != 2 || math.Abs(accumulator - 0.05) > 1e-9 {
+		t.Errorf("long frame runs %d updates and leaves %v, expected 2 and 0.05", updates, accumulator)
+	}
+
+	// The clock going back doesn't take time away
+	if updates, accumulator = stepTimestep(0.05, -1, 0.1); updates != 0 || accumulator != 0.05 {
+		t.Errorf("negative delta runs %d updates and leaves %v, expected 0 and 0.05", updates, accumulator)
+	}
+}
+
+func TestStepTimestepCapsTheUpdates(t *testing.T) {
+	clock := &fakeClock{}
+	timestep := 0.001
+
+	// Every frame is slower than the updates it would need, the updates per frame are capped
+	// and the time left never grows past a timestep
+	for frame := 0; frame < 100; frame++ {
+		updates, alpha := clock.frame(0.1, timestep)
+		if updates != maxUpdatesPerFrame {
+			t.Fatalf("frame %d runs %d updates, expected %d", frame, updates, maxUpdatesPerFrame)
+		}
+		if alpha < 0 || alpha >= 1 {
+			t.Fatalf("frame %d has alpha %v, expected between 0 and 1", frame, alpha)
+		}
+	}
+}
+
+func TestSetTimestep(t *testing.T) {
+	glw := NewWrapper(1, 1, "")
+
+	for _, seconds := range []float64{0, -0.01, math.NaN(), math.Inf(1)} {
+		if err := glw.SetTimestep(seconds); err == nil {
+			t.Errorf("expected an error for a timestep of %v", seconds)
+		}
+	}
+	if timestep := glw.GetTimestep(); timestep != 1.0 / 60.0 {
+		t.Errorf("timestep is %v after the invalid ones, expected 1/60", timestep)
+	}
+
+	if err := glw.SetTimestep(0.01); err != nil || glw.GetTimestep() != 0.01 {
+		t.Errorf("timestep is %v (%v), expected 0.01", glw.GetTimestep(), err)
+	}
+}
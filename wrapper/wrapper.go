@@ -2,13 +2,27 @@ package wrapper
 
 import (
 	"runtime"
-	"log"
 	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"../imaging"
+	"../gpu"
 
 	"github.com/go-gl/gl/all-core/gl"
 	"github.com/go-gl/glfw/v3.1/glfw"
 )
 
+// Timing of the last frame of the loop
+type FrameTiming struct {
+	DeltaTime float64 // Seconds between the start of the last frame and the start of the one before it
+	FrameTime float64 // Seconds spent updating and rendering the last frame (without the frame rate limit)
+	Alpha float64 // How far the last frame is between the last update and the next one (0 to 1), to interpolate
+	Updates int // Number of updates run in the last frame
+	FrameCount uint64 // Number of frames rendered since the loop started
+}
+
 type Glw struct  {
 	// Properties
 	Width, Height int
@@ -19,10 +33,27 @@ type Glw struct  {
 	running bool
 	Window *glfw.Window
 
+	// Fixed timestep of the update callback (in seconds) and the timing of the last frame
+	timestep float64
+	timing FrameTiming
+
+	// Frame statistics (CPU and GPU time, draw calls and triangles)
+	stats *Stats
+
+	// Offscreen rendering (frames are rendered to a framebuffer object and saved as PNG)
+	offscreenFrames int
+	framePattern string
+	framebuffer *Framebuffer
+
 	// Callbacks
 	renderer func(glw *Glw)
+	updater func(glw *Glw, dt float64)
+	releaser func(glw *Glw)
 	keyCallBack glfw.KeyCallback
 	reshape glfw.FramebufferSizeCallback
+	mouseButtonCallBack glfw.MouseButtonCallback
+	cursorPosCallBack glfw.CursorPosCallback
+	scrollCallBack glfw.ScrollCallback
 }
 
 // This function is called by go as soon as this library is imported
@@ -42,89 +73,258 @@ func init () {
 // @return wrapper (*Glw) a pointer to the wrapper.
 //
 func NewWrapper(width, height int, title string) *Glw {
-	return &Glw{ width, height, title, 60, true, nil, nil, nil, nil }
+	return &Glw{
+		width, height, title, // properties
+		60, true, nil, // fps, running, window
+		1.0 / 60.0, FrameTiming{}, // timestep, timing
+		NewStats(), // stats
+		0, "", nil, // offscreen frames, frame pattern, framebuffer
+		nil, nil, nil, nil, nil, // renderer, updater, releaser, key callback, reshape
+		nil, nil, nil, // mouse button, cursor position and scroll callbacks
+	}
 }
 
 // Public Functions
 
 //
 // Create Window
-// This creates a window, initiates GL and sets the event listeners
+// This creates a window, initiates GL and sets the event listeners.
+// If anything fails, what was already created is destroyed again.
 //
 // @return window (*glfw.Window) pointer to the window
+// @return error (error) the error (a *ContextError if there is no display or OpenGL 3.3 isn't supported)
 //
-func (glw *Glw) CreateWindow () *glfw.Window {
+func (glw *Glw) CreateWindow () (*glfw.Window, error) {
 	// Init GLFW
 	if err := glfw.Init(); err != nil {
-		log.Fatalln("failed to initialize glfw:", err)
+		return nil, &ContextError{"initialize glfw", err}
 	}
 
 	// Sets the OpenGL Version
 	setOpenGlVersion()
 
+	// When rendering offscreen the window is only needed for the GL context, so it is kept hidden
+	if glw.IsOffscreen() {
+		glfw.WindowHint(glfw.Visible, glfw.False)
+	}
+
 	// Creates the Window
 	win, err := glfw.CreateWindow(glw.Width, glw.Height, glw.Title, nil, nil)
 	if err != nil {
-		panic(err)
+		glfw.Terminate()
+		return nil, &ContextError{"create the window", err}
 	}
 
-	// Prints the OpenGL Versions at the end
-	defer printOpenGlVersionInfo()
-
 	// Sets this context as the current context
 	win.MakeContextCurrent()
 
 	// Initiates GL
 	if err := gl.Init(); err != nil {
-		panic(err)
+		win.Destroy()
+		glfw.Terminate()
+		return nil, &ContextError{"initialize OpenGL", err}
 	}
 
+	// Prints the OpenGL Versions at the end
+	defer printOpenGlVersionInfo()
+
 	// Enables Depth
 	gl.Enable(gl.DEPTH_TEST)
 	gl.DepthFunc(gl.LESS)
 
 	win.SetInputMode(glfw.StickyKeysMode, 1)
 
+	// Creates the framebuffer the offscreen frames are rendered to
+	if glw.IsOffscreen() {
+		framebuffer, err := NewFramebuffer(glw.Width, glw.Height)
+		if err != nil {
+			win.Destroy()
+			glfw.Terminate()
+			return nil, &ContextError{"create the offscreen framebuffer", err}
+		}
+
+		glw.framebuffer = framebuffer
+		framebuffer.Bind()
+	}
+
 	// Sets the Window to the Wrapper
 	glw.SetWindow(win)
-	return win
+	return win, nil
 }
 
 //
 // Start Loop
-// this starts the event loop which runs until the program ends
+// this starts the event loop which runs until the program ends.
+// The update callback runs on a fixed timestep (as many times as needed to catch up with the real time),
+// the render callback runs once per frame and the frame rate is limited to the configured FPS.
+//
+// @return error (error) the error (if a frame rendered offscreen couldn't be saved)
 //
-func (glw *Glw) StartLoop () {
+func (glw *Glw) StartLoop () error {
+
+	// Offscreen, render the requested frames and finish
+	if glw.IsOffscreen() {
+		err := glw.renderOffscreen()
+		glw.Terminate()
+		return err
+	}
+
+	var accumulator float64
+	previous := glfw.GetTime()
 
 	// If the Window is open keep looping
 	for !glw.GetWindow().ShouldClose() {
+		frameStart := glfw.GetTime()
+		glw.stats.BeginFrame()
+
+		// Time passed since the last frame
+		glw.timing.DeltaTime = frameStart - previous
+		previous = frameStart
+
+		// Runs the updates that fit in the time passed, the rest is left for the next frame
+		var updates int
+		updates, accumulator = stepTimestep(accumulator, glw.timing.DeltaTime, glw.timestep)
+
+		glw.timing.Updates = 0
+		for update := 0; update < updates; update++ {
+			glw.update(glw.timestep)
+		}
+		glw.timing.Alpha = accumulator / glw.timestep
 
 		// Calls the Render Callback
 		glw.renderer(glw)
+		glw.stats.EndFrame()
 
 		// Triggers window refresh
 		glw.GetWindow().SwapBuffers()
 
 		// Triggers events
 		glfw.PollEvents()
+
+		// Prints the frame statistics periodically (if enabled)
+		if glw.stats.ShouldReport() {
+			glw.showStatsReport()
+		}
+
+		glw.timing.FrameTime = glfw.GetTime() - frameStart
+		glw.timing.FrameCount++
+
+		// Waits for the rest of the frame if it finished early
+		glw.limitFrameRate(frameStart)
 	}
 
 	// Called at the end of the program, and terminates the window system
 	glw.Terminate()
+	return nil
+}
+
+//
+// Capture Frame
+// Renders one frame into the offscreen framebuffer and reads it back (only in offscreen mode)
+//
+// @return image (*image.RGBA) the rendered frame
+//
+func (glw *Glw) CaptureFrame () *image.RGBA {
+	glw.framebuffer.Bind()
+	glw.renderer(glw)
+	return glw.framebuffer.ReadPixels()
 }
 
 //
 // Terminate
-// When this is called, it destroys the window and terminates glfw
+// When this is called, it releases the GPU objects (and reports the ones that leaked), destroys the window and terminates glfw
 //
 func (glw *Glw) Terminate () {
 	// Clean up
+	if glw.releaser != nil {
+		glw.releaser(glw)
+	}
+
+	glw.stats.Release()
+
+	if glw.framebuffer != nil {
+		glw.framebuffer.Release()
+		glw.framebuffer = nil
+	}
+
+	// Everything should be released by now, the GPU objects that weren't are leaked
+	fmt.Print(gpu.ResourceReport())
+
 	glw.Window.Destroy()
 	glfw.Terminate()
 }
 
 // Private Functions
 
+//
+// render Offscreen
+// Renders the requested number of frames and saves each of them as a PNG file
+//
+// @return error (error) the error (if a frame couldn't be saved)
+//
+func (glw *Glw) renderOffscreen() error {
+	for frame := 0; frame < glw.offscreenFrames; frame++ {
+		path := FramePath(glw.framePattern, frame, glw.offscreenFrames)
+
+		if err := imaging.SavePNG(path, glw.CaptureFrame()); err != nil {
+			return fmt.Errorf("failed to save frame: %v", err)
+		}
+		fmt.Println("Saved frame", path)
+		glw.timing.FrameCount++
+
+		// Every frame advances the animation by one timestep, so the frames are the same on every machine
+		glw.timing.Updates = 0
+		glw.update(glw.timestep)
+
+		// Keeps the window system responsive
+		glfw.PollEvents()
+	}
+
+	return nil
+}
+
+//
+// show Stats Report
+// Prints the frame statistics and shows them in the window title
+//
+func (glw *Glw) showStatsReport() {
+	report := glw.stats.Report()
+	fmt.Println(report)
+	glw.Window.SetTitle(fmt.Sprintf("%s | %.0f fps | cpu %.2fms | gpu %.2fms | %.0f draws | %.0f tris",
+		glw.Title, report.FPS, report.CPUAverage, report.GPUAverage, report.DrawCalls, report.Triangles))
+}
+
+//
+// update
+// Calls the update callback (if any)
+//
+// @param dt (float64) the seconds the update advances the app
+//
+func (glw *Glw) update(dt float64) {
+	glw.timing.Updates++
+
+	if glw.updater != nil {
+		glw.updater(glw, dt)
+	}
+}
+
+//
+// limit Frame Rate
+// Sleeps until the time of a frame at the configured FPS has passed
+//
+// @param frameStart (float64) the time the frame started (from glfw.GetTime)
+//
+func (glw *Glw) limitFrameRate(frameStart float64) {
+	if glw.fps <= 0 {
+		return
+	}
+
+	remaining := 1.0 / float64(glw.fps) - (glfw.GetTime() - frameStart)
+	if remaining > 0 {
+		time.Sleep(time.Duration(remaining * float64(time.Second)))
+	}
+}
+
 //
 // set OpenGl Version
 // Sets the openGL version to the window
@@ -155,6 +355,7 @@ func printOpenGlVersionInfo() {
 
 // Getters & Setters
 
+// A FPS of 0 or less doesn't limit the frame rate
 func (glw *Glw) SetFPS (fps int) {
 	glw.fps = fps
 }
@@ -163,6 +364,26 @@ func (glw *Glw) GetFPS () int {
 	return glw.fps
 }
 
+//
+// Set Offscreen
+// Renders to a framebuffer object in a hidden window instead of the visible window (call before CreateWindow)
+//
+// @param frames (int) the number of frames to render before the loop ends
+// @param pattern (string) the path the frames are saved to (see FramePath)
+//
+func (glw *Glw) SetOffscreen (frames int, pattern string) {
+	glw.offscreenFrames = frames
+	glw.framePattern = pattern
+}
+
+func (glw *Glw) IsOffscreen () bool {
+	return glw.offscreenFrames > 0
+}
+
+func (glw *Glw) GetFramebuffer () *Framebuffer {
+	return glw.framebuffer
+}
+
 func (glw *Glw) SetWindow (window *glfw.Window) {
 	glw.Window = window
 }
@@ -171,10 +392,73 @@ func (glw *Glw) GetWindow () *glfw.Window {
 	return glw.Window
 }
 
+// Returns the size (in pixels) of what is rendered to: the offscreen framebuffer, or the window's framebuffer
+// (which is bigger than the window on high DPI screens)
+func (glw *Glw) GetFramebufferSize () (int, int) {
+	if glw.framebuffer != nil {
+		return glw.framebuffer.Width, glw.framebuffer.Height
+	}
+	if glw.Window == nil {
+		return glw.Width, glw.Height
+	}
+
+	return glw.Window.GetFramebufferSize()
+}
+
 func (glw *Glw) SetRenderCallback (callback func(glw *Glw)) {
 	glw.renderer = callback
 }
 
+// The update callback receives the fixed timestep in seconds
+func (glw *Glw) SetUpdateCallback (callback func(glw *Glw, dt float64)) {
+	glw.updater = callback
+}
+
+// The release callback runs in Terminate, while the context is still alive, to delete the GPU objects of the app
+func (glw *Glw) SetReleaseCallback (callback func(glw *Glw)) {
+	glw.releaser = callback
+}
+
+//
+// Set Timestep
+// Sets how many seconds each update advances the app (1 / 60 by default)
+//
+// @param seconds (float64) the timestep, more than 0
+//
+// @return error (error) the error (if the timestep isn't a positive number, the timestep doesn't change)
+//
+func (glw *Glw) SetTimestep (seconds float64) error {
+	if !(seconds > 0) || math.IsInf(seconds, 1) {
+		return fmt.Errorf("invalid timestep %v, it has to be a positive number of seconds", seconds)
+	}
+
+	glw.timestep = seconds
+	return nil
+}
+
+func (glw *Glw) GetTimestep () float64 {
+	return glw.timestep
+}
+
+func (glw *Glw) GetTiming () FrameTiming {
+	return glw.timing
+}
+
+func (glw *Glw) GetStats () *Stats {
+	return glw.stats
+}
+
+// Shows or hides the periodic frame statistics report (console and window title)
+func (glw *Glw) ToggleStats () {
+	glw.stats.Visible = !glw.stats.Visible
+
+	if glw.stats.Visible {
+		glw.showStatsReport()
+	} else {
+		glw.Window.SetTitle(glw.Title)
+	}
+}
+
 func (glw *Glw) SetKeyCallBack (callback glfw.KeyCallback) {
 	glw.keyCallBack = callback
 	glw.Window.SetKeyCallback(callback)
@@ -183,4 +467,19 @@ func (glw *Glw) SetKeyCallBack (callback glfw.KeyCallback) {
 func (glw *Glw) SetReshapeCallback (callback glfw.FramebufferSizeCallback) {
 	glw.reshape = callback
 	glw.Window.SetFramebufferSizeCallback(callback)
-}
\ No newline at end of file
+}
+
+func (glw *Glw) SetMouseButtonCallback (callback glfw.MouseButtonCallback) {
+	glw.mouseButtonCallBack = callback
+	glw.Window.SetMouseButtonCallback(callback)
+}
+
+func (glw *Glw) SetCursorPosCallback (callback glfw.CursorPosCallback) {
+	glw.cursorPosCallBack = callback
+	glw.Window.SetCursorPosCallback(callback)
+}
+
+func (glw *Glw) SetScrollCallback (callback glfw.ScrollCallback) {
+	glw.scrollCallBack = callback
+	glw.Window.SetScrollCallback(callback)
+}